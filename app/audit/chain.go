@@ -0,0 +1,308 @@
+package audit
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const chainVerifyBatch = 1000
+
+type chainKey struct {
+	entityType models.EntityType
+	entityID   uuid.UUID
+}
+
+// VerifyChain walks the audit chains, all of them or the ones matching the
+// filter, and stops at the first broken link. Besides recomputing every hash
+// it compares chains against their latest anchor, which catches entries
+// deleted from the end of a chain or a chain rewritten as a whole. Chains
+// whose oldest entries were archived continue from the archived head.
+func VerifyChain(entityType models.EntityType, entityID *uuid.UUID) (*dto.AuditChainReport, error) {
+	repo := repository.NewAuditRepository()
+	report := &dto.AuditChainReport{}
+
+	latestAnchors, err := repo.FindLatestAnchors(entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveHeads, err := repository.NewAuditArchiveRepository().FindArchiveHeads(entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make(map[chainKey]models.AuditLog, len(archiveHeads))
+	for _, head := range archiveHeads {
+		heads[chainKey{head.EntityType, head.EntityID}] = models.AuditLog{
+			EntityType: head.EntityType,
+			EntityID:   head.EntityID,
+			Seq:        head.Seq,
+			Hash:       head.Hash,
+		}
+	}
+
+	anchors := make(map[chainKey]models.AuditChainAnchor, len(latestAnchors))
+	for _, anchor := range latestAnchors {
+		key := chainKey{anchor.EntityType, anchor.EntityID}
+		if head, ok := heads[key]; ok && head.Seq >= anchor.Seq {
+			continue
+		}
+		anchors[key] = anchor
+	}
+
+	redactions := make(map[uuid.UUID][]models.AuditLog)
+
+	var prev *models.AuditLog
+	var current *chainKey
+	var after *repository.AuditChainPosition
+	for {
+		logs, err := repo.FindChain(entityType, entityID, after, chainVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range logs {
+			log := logs[i]
+			key := chainKey{log.EntityType, log.EntityID}
+
+			if current == nil || *current != key {
+				if prev != nil {
+					if report.Break = closeChain(prev, anchors); report.Break != nil {
+						return report, nil
+					}
+				}
+
+				current, prev = &key, nil
+				if head, ok := heads[key]; ok {
+					prev = &head
+				}
+				report.Chains++
+			}
+			report.Entries++
+
+			// left behind below the archived head, already covered by the archive
+			if head, ok := heads[key]; ok && log.Seq <= head.Seq {
+				continue
+			}
+
+			if report.Break = verifyLink(prev, &log, anchors, redactions, report); report.Break != nil {
+				return report, nil
+			}
+			prev = &log
+		}
+
+		if len(logs) < chainVerifyBatch {
+			break
+		}
+
+		last := logs[len(logs)-1]
+		after = &repository.AuditChainPosition{EntityType: last.EntityType, EntityID: last.EntityID, Seq: last.Seq}
+	}
+
+	if prev != nil {
+		if report.Break = closeChain(prev, anchors); report.Break != nil {
+			return report, nil
+		}
+	}
+
+	for _, anchor := range anchors {
+		report.Break = &dto.AuditChainBreak{
+			EntityType: anchor.EntityType.String(),
+			EntityID:   anchor.EntityID,
+			Seq:        anchor.Seq,
+			Reason:     "anchored chain has no entries left",
+		}
+		return report, nil
+	}
+
+	if report.Break, err = verifyRedactions(repo, redactions); err != nil || report.Break != nil {
+		return report, err
+	}
+
+	report.Valid = true
+	return report, nil
+}
+
+func verifyLink(prev, log *models.AuditLog, anchors map[chainKey]models.AuditChainAnchor, redactions map[uuid.UUID][]models.AuditLog, report *dto.AuditChainReport) *dto.AuditChainBreak {
+	broken := func(reason string) *dto.AuditChainBreak {
+		return entryBreak(log, reason)
+	}
+
+	if log.Hash == "" {
+		report.Unsealed++
+		return nil
+	}
+
+	expectedSeq, expectedPrevHash := int64(1), ""
+	if prev != nil {
+		expectedSeq, expectedPrevHash = prev.Seq+1, prev.Hash
+	}
+
+	if log.Seq != expectedSeq {
+		return broken("entries missing before seq " + strconv.FormatInt(log.Seq, 10))
+	}
+
+	if log.PrevHash != expectedPrevHash {
+		return broken("previous hash does not match the previous entry")
+	}
+
+	// personal data may only differ from its hash on entries a redaction
+	// lists, checked once the walk is done. Entries sealed before it was
+	// hashed apart are covered by a single hash, erasing them loses it.
+	if log.ComputeContentHash() != log.ContentHash {
+		if log.PiiHash != "" || log.RedactedBy == nil {
+			return broken("content does not match its hash")
+		}
+		report.Redacted++
+		redactions[*log.RedactedBy] = append(redactions[*log.RedactedBy], *log)
+	} else if log.PiiHash != "" && log.ComputePiiHash() != log.PiiHash {
+		if log.RedactedBy == nil {
+			return broken("personal data does not match its hash")
+		}
+		report.Redacted++
+		redactions[*log.RedactedBy] = append(redactions[*log.RedactedBy], *log)
+	}
+
+	if models.AuditChainHash(log.PrevHash, log.ContentHash) != log.Hash {
+		return broken("hash does not match the chained content")
+	}
+
+	anchor, ok := anchors[chainKey{log.EntityType, log.EntityID}]
+	if ok && anchor.Seq == log.Seq && anchor.Hash != log.Hash {
+		return broken("hash does not match the anchored chain head")
+	}
+
+	return nil
+}
+
+// verifyRedactions checks that every entry with erased personal data is
+// listed by the redaction entry it points to. The redaction entries are
+// verified on their own chain, here they only need to be intact themselves;
+// one not sealed yet is trusted like any unsealed entry.
+func verifyRedactions(repo *repository.AuditRepository, redactions map[uuid.UUID][]models.AuditLog) (*dto.AuditChainBreak, error) {
+	if len(redactions) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(redactions))
+	for id := range redactions {
+		ids = append(ids, id)
+	}
+
+	entries, err := repo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[uuid.UUID]map[uuid.UUID]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Action != models.ActionRedact {
+			continue
+		}
+		if entry.Hash != "" && (entry.ComputeContentHash() != entry.ContentHash ||
+			models.AuditChainHash(entry.PrevHash, entry.ContentHash) != entry.Hash) {
+			continue
+		}
+
+		var redaction models.AuditRedaction
+		if err := json.Unmarshal([]byte(entry.Details), &redaction); err != nil {
+			continue
+		}
+
+		listed[entry.ID] = make(map[uuid.UUID]bool, len(redaction.Entries))
+		for _, id := range redaction.Entries {
+			listed[entry.ID][id] = true
+		}
+	}
+
+	for id, logs := range redactions {
+		for i := range logs {
+			if !listed[id][logs[i].ID] {
+				return entryBreak(&logs[i], "personal data was removed outside of a recorded redaction"), nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func entryBreak(log *models.AuditLog, reason string) *dto.AuditChainBreak {
+	id := log.ID
+	return &dto.AuditChainBreak{
+		EntityType: log.EntityType.String(),
+		EntityID:   log.EntityID,
+		Seq:        log.Seq,
+		EntryID:    &id,
+		Reason:     reason,
+	}
+}
+
+// closeChain checks that a chain still reaches its anchored head and marks
+// the anchor as seen.
+func closeChain(last *models.AuditLog, anchors map[chainKey]models.AuditChainAnchor) *dto.AuditChainBreak {
+	key := chainKey{last.EntityType, last.EntityID}
+	anchor, ok := anchors[key]
+	if !ok {
+		return nil
+	}
+	delete(anchors, key)
+
+	if anchor.Seq > last.Seq {
+		return &dto.AuditChainBreak{
+			EntityType: last.EntityType.String(),
+			EntityID:   last.EntityID,
+			Seq:        last.Seq + 1,
+			Reason:     "entries up to anchored seq " + strconv.FormatInt(anchor.Seq, 10) + " are missing",
+		}
+	}
+
+	return nil
+}
+
+// AnchorChains seals entries written before chaining existed and records the
+// current head of every chain that grew. The digest of the new anchors is
+// logged as well, so a copy of the anchors lives outside the database.
+func AnchorChains() error {
+	repo := repository.NewAuditRepository()
+
+	sealed, err := repo.SealLegacy()
+	if err != nil {
+		return err
+	}
+	if sealed > 0 {
+		utils.Logger.Info("✅ SEALED " + strconv.Itoa(sealed) + " LEGACY AUDIT CHAINS")
+	}
+
+	anchors, err := repo.Anchor()
+	if err != nil {
+		return err
+	}
+	if len(anchors) == 0 {
+		return nil
+	}
+
+	sort.Slice(anchors, func(i, j int) bool {
+		if anchors[i].EntityType != anchors[j].EntityType {
+			return anchors[i].EntityType < anchors[j].EntityType
+		}
+		return anchors[i].EntityID.String() < anchors[j].EntityID.String()
+	})
+
+	digest := sha256.New()
+	for _, anchor := range anchors {
+		fmt.Fprintf(digest, "%d:%s:%d:%s\n", anchor.EntityType, anchor.EntityID, anchor.Seq, anchor.Hash)
+	}
+
+	utils.Logger.Info("✅ ANCHORED " + strconv.Itoa(len(anchors)) + " AUDIT CHAINS, DIGEST " + hex.EncodeToString(digest.Sum(nil)))
+	return nil
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"backend-path/app/middlewares"
+	"backend-path/app/models"
+	"encoding/json"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FromRequest collects the acting user and the request identifiers that
+// are stored with every audit entry.
+func FromRequest(ctx *fiber.Ctx) models.AuditContext {
+	auditContext := models.AuditContext{
+		RequestID: middlewares.GetRequestID(ctx),
+		IP:        ctx.IP(),
+	}
+
+	if userID, ok := ctx.Locals("user_auth").(string); ok {
+		if actorID, err := uuid.Parse(userID); err == nil {
+			auditContext.ActorUserID = &actorID
+		}
+	}
+
+	if role, ok := ctx.Locals("user_role").(models.Role); ok {
+		auditContext.ActorRole = role.String()
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx.UserContext()); spanContext.HasTraceID() {
+		auditContext.TraceID = spanContext.TraceID().String()
+	}
+
+	return auditContext
+}
+
+// Diff compares the JSON form of two values and returns the changed fields
+// as {"field": {"from": ..., "to": ...}}, or an empty string when nothing
+// changed.
+func Diff(before, after interface{}) string {
+	beforeFields, afterFields := jsonFields(before), jsonFields(after)
+
+	changes := map[string]map[string]interface{}{}
+	for name, value := range afterFields {
+		if previous := beforeFields[name]; !reflect.DeepEqual(previous, value) {
+			changes[name] = map[string]interface{}{"from": previous, "to": value}
+		}
+	}
+
+	for name, previous := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = map[string]interface{}{"from": previous, "to": nil}
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	changesJSON, _ := json.Marshal(changes)
+	return string(changesJSON)
+}
+
+func jsonFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil {
+		return fields
+	}
+
+	data, _ := json.Marshal(value)
+	json.Unmarshal(data, &fields)
+	return fields
+}
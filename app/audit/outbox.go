@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"backend-path/app/metrics"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/utils"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IOutbox interface {
+	Record(auditLog *models.AuditLog)
+	RecordTx(tx *gorm.DB, auditLog *models.AuditLog) error
+}
+
+// Outbox persists audit events in the audit_outbox table and relays them
+// into the hash chained audit log in batches every flush interval. Writing
+// an event is a single insert, chaining it takes the chain lock and happens
+// off the request path. Every instance relays, events are claimed with SKIP
+// LOCKED and deleted in the transaction that chains them, so each event is
+// chained once. Events refused by the database stay in the table for the
+// next round.
+type Outbox struct {
+	repo          repository.IAuditOutboxRepository
+	batchSize     int
+	flushInterval time.Duration
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+var (
+	outboxInstance *Outbox
+	outboxOnce     sync.Once
+)
+
+func NewOutbox() *Outbox {
+	outboxOnce.Do(func() {
+		batchSize, _ := strconv.Atoi(os.Getenv("AUDIT_OUTBOX_BATCH"))
+		if batchSize == 0 {
+			batchSize = 100
+		}
+
+		outboxInstance = &Outbox{
+			repo:          repository.NewAuditOutboxRepository(),
+			batchSize:     batchSize,
+			flushInterval: time.Second,
+			stop:          make(chan struct{}),
+			done:          make(chan struct{}),
+		}
+		go outboxInstance.run()
+	})
+
+	return outboxInstance
+}
+
+// StopOutbox relays the events waiting in the outbox, if the outbox was
+// started at all.
+func StopOutbox() {
+	if outboxInstance != nil {
+		outboxInstance.Stop()
+	}
+}
+
+// Record stores an event that is not part of a database transaction. Only
+// an event the database refuses is dropped and counted in
+// audit_events_dropped_total.
+func (o *Outbox) Record(auditLog *models.AuditLog) {
+	if err := o.RecordTx(nil, auditLog); err != nil {
+		utils.Logger.Error("❌ AUDIT OUTBOX ERROR, DROPPED 1 EVENT: " + err.Error())
+		metrics.AuditEventsDroppedTotal.Inc()
+	}
+}
+
+// RecordTx stores the event within tx, it is recorded only if tx commits.
+// The id and timestamp are fixed here so the entry reflects when it
+// happened.
+func (o *Outbox) RecordTx(tx *gorm.DB, auditLog *models.AuditLog) error {
+	event := *auditLog
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if err := o.repo.Insert(tx, &event); err != nil {
+		return err
+	}
+
+	metrics.AuditEventsQueuedTotal.Inc()
+	return nil
+}
+
+func (o *Outbox) Stop() {
+	o.stopOnce.Do(func() {
+		close(o.stop)
+		<-o.done
+		utils.Logger.Info("✅ AUDIT OUTBOX FLUSHED")
+	})
+}
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	ticker := time.NewTicker(o.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		o.relay()
+
+		select {
+		case <-ticker.C:
+		case <-o.stop:
+			o.relay()
+			return
+		}
+	}
+}
+
+// relay chains batches of waiting events until the outbox is drained or
+// the database fails, and reports what is left.
+func (o *Outbox) relay() {
+	for {
+		relayed, err := o.repo.Relay(o.batchSize)
+		if err != nil {
+			utils.Logger.Error("❌ AUDIT OUTBOX RELAY ERROR: " + err.Error())
+			break
+		}
+
+		if relayed < o.batchSize {
+			break
+		}
+	}
+
+	// the relay loop is the gauge's only writer, the count covers events
+	// recorded by every instance
+	depth, err := o.repo.Count()
+	if err != nil {
+		return
+	}
+	metrics.AuditOutboxQueueDepth.Set(float64(depth))
+}
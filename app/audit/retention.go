@@ -0,0 +1,288 @@
+package audit
+
+import (
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/storage"
+	"backend-path/utils"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const auditPartitionsAhead = 3
+
+// defaultRetentionDays keeps financial entries for seven years and account
+// activity for shorter periods.
+var defaultRetentionDays = map[models.EntityType]int{
+	models.EntityUser:          730,
+	models.EntityTransaction:   2555,
+	models.EntityBalance:       2555,
+	models.EntityRole:          730,
+	models.EntityApiKey:        365,
+	models.EntityKycSubmission: 1825,
+}
+
+// Retention is how long entries of a type stay in the database, configured
+// with AUDIT_RETENTION_<TYPE>_DAYS.
+func Retention(entityType models.EntityType) time.Duration {
+	days, _ := strconv.Atoi(os.Getenv("AUDIT_RETENTION_" + strings.ToUpper(entityType.String()) + "_DAYS"))
+	if days == 0 {
+		days = defaultRetentionDays[entityType]
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// EnsurePartitions creates the partitions for the current month and the
+// next few, so entries never end up in the default partition.
+func EnsurePartitions() error {
+	repo := repository.NewAuditArchiveRepository()
+
+	now := time.Now().UTC()
+	for i := 0; i <= auditPartitionsAhead; i++ {
+		if err := repo.CreatePartition(now.AddDate(0, i, 0)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MaintainPartitions creates upcoming partitions and archives expired
+// entries. Entries whose retention ended are exported to the blob store and
+// deleted; partitions in which every entry expired are detached and dropped.
+func MaintainPartitions() error {
+	if err := EnsurePartitions(); err != nil {
+		return err
+	}
+
+	archiver := &partitionArchiver{
+		repo:      repository.NewAuditArchiveRepository(),
+		blobStore: storage.NewBlobStore(),
+	}
+
+	detached, err := archiver.repo.ListDetachedPartitions()
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range detached {
+		if err := archiver.dropDetached(partition, -1); err != nil {
+			return err
+		}
+	}
+
+	partitions, err := archiver.repo.ListPartitions()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, partition := range partitions {
+		expired := true
+		for entityType := models.EntityUser; entityType.IsValid(); entityType++ {
+			if partition.To.After(now.Add(-Retention(entityType))) {
+				expired = false
+			}
+		}
+
+		if expired {
+			if err := archiver.archivePartition(partition); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for entityType := models.EntityUser; entityType.IsValid(); entityType++ {
+			cutoff := now.Add(-Retention(entityType))
+			if !partition.From.Before(cutoff) {
+				continue
+			}
+
+			if err := archiver.archive(partition, entityType, cutoff); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type partitionArchiver struct {
+	repo      repository.IAuditArchiveRepository
+	blobStore storage.BlobStore
+}
+
+// archivePartition exports a partition in which every entry expired under
+// a SHARE lock, which only holds off writers to that partition, then
+// detaches and drops it. The parent table is not locked during the export.
+func (a *partitionArchiver) archivePartition(partition repository.AuditPartition) error {
+	var exported int64
+	err := a.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := a.repo.LockPartition(tx, partition); err != nil {
+			return err
+		}
+
+		archive, err := a.store(tx, partition, 0, partition.To)
+		if err != nil {
+			return err
+		}
+		exported = archive.RowCount
+
+		return a.repo.SaveArchiveHeads(tx, partition)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := a.repo.DetachPartition(partition); err != nil {
+		return err
+	}
+
+	return a.dropDetached(partition, exported)
+}
+
+// dropDetached drops a detached partition. Nothing can write to it anymore,
+// so when it holds other than the exported number of entries, or that is
+// unknown (-1), it is exported again as a whole before it goes.
+func (a *partitionArchiver) dropDetached(partition repository.AuditPartition, exported int64) error {
+	return a.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		count, err := a.repo.CountExpired(tx, partition, 0, partition.To)
+		if err != nil {
+			return err
+		}
+
+		if count != exported {
+			if _, err := a.store(tx, partition, 0, partition.To); err != nil {
+				return err
+			}
+			if err := a.repo.SaveArchiveHeads(tx, partition); err != nil {
+				return err
+			}
+		}
+
+		if err := a.repo.DropPartition(tx, partition); err != nil {
+			return err
+		}
+
+		utils.Logger.Info("✅ ARCHIVED AND DROPPED AUDIT PARTITION " + partition.Name + " (" + strconv.FormatInt(count, 10) + " ENTRIES)")
+		return nil
+	})
+}
+
+// archive exports and removes the expired entries of one type in a single
+// transaction. Nothing is deleted unless the export was stored.
+func (a *partitionArchiver) archive(partition repository.AuditPartition, entityType models.EntityType, before time.Time) error {
+	return a.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := a.repo.LockPartition(tx, partition); err != nil {
+			return err
+		}
+
+		count, err := a.repo.CountExpired(tx, partition, entityType, before)
+		if err != nil || count == 0 {
+			return err
+		}
+
+		if _, err := a.store(tx, partition, entityType, before); err != nil {
+			return err
+		}
+
+		deleted, err := a.repo.DeleteExpired(tx, partition, entityType, before)
+		if err != nil {
+			return err
+		}
+
+		utils.Logger.Info("✅ ARCHIVED " + strconv.FormatInt(deleted, 10) + " " + strings.ToUpper(entityType.String()) + " AUDIT ENTRIES FROM " + partition.Name)
+		return nil
+	})
+}
+
+// store exports the expired entries, or every entry of the partition when
+// entityType is 0, and records the archive. Nothing is recorded for an
+// empty export.
+func (a *partitionArchiver) store(tx *gorm.DB, partition repository.AuditPartition, entityType models.EntityType, before time.Time) (*models.AuditLogArchive, error) {
+	scope := "all"
+	var archivedType *models.EntityType
+	if entityType != 0 {
+		scope = entityType.String()
+		archivedType = &entityType
+	}
+
+	archive := &models.AuditLogArchive{
+		PartitionName: partition.Name,
+		EntityType:    archivedType,
+		BlobKey:       "audit-archive/" + partition.Name + "/" + scope + "-" + time.Now().UTC().Format("20060102150405.000000") + ".jsonl.gz",
+	}
+
+	if err := a.export(tx, partition, entityType, before, archive); err != nil {
+		return nil, err
+	}
+
+	if archive.RowCount == 0 {
+		a.blobStore.Delete(archive.BlobKey)
+		return archive, nil
+	}
+
+	return archive, a.repo.CreateArchive(tx, archive)
+}
+
+// export streams the entries as gzipped JSONL to the blob store and fills in
+// the row count and the SHA-256 of the uncompressed content.
+func (a *partitionArchiver) export(tx *gorm.DB, partition repository.AuditPartition, entityType models.EntityType, before time.Time, archive *models.AuditLogArchive) error {
+	rows, err := a.repo.FindExpiredRows(tx, partition, entityType, before)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	pipeReader, pipeWriter := io.Pipe()
+	putErr := make(chan error, 1)
+	go func() {
+		err := a.blobStore.Put(archive.BlobKey, pipeReader)
+		pipeReader.CloseWithError(err)
+		putErr <- err
+	}()
+
+	digest := sha256.New()
+	compressor := gzip.NewWriter(pipeWriter)
+	encoder := json.NewEncoder(io.MultiWriter(compressor, digest))
+
+	var writeErr error
+	for rows.Next() {
+		var log models.AuditLog
+		if writeErr = tx.ScanRows(rows, &log); writeErr != nil {
+			break
+		}
+		if writeErr = encoder.Encode(log); writeErr != nil {
+			break
+		}
+		archive.RowCount++
+	}
+
+	if writeErr == nil {
+		writeErr = rows.Err()
+	}
+	if writeErr == nil {
+		writeErr = compressor.Close()
+	}
+	pipeWriter.CloseWithError(writeErr)
+
+	if err := <-putErr; err != nil {
+		return err
+	}
+	if writeErr != nil {
+		a.blobStore.Delete(archive.BlobKey)
+		return writeErr
+	}
+
+	archive.Sha256 = hex.EncodeToString(digest.Sum(nil))
+	return nil
+}
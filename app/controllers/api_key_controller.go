@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ApiKeyController struct {
+	apiKeyService services.IApiKeyService
+}
+
+func NewApiKeyController() *ApiKeyController {
+	return &ApiKeyController{
+		apiKeyService: services.NewApiKeyService(),
+	}
+}
+
+func (c *ApiKeyController) Create(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.CreateApiKeyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_INVALID_REQUEST")
+	}
+
+	return c.apiKeyService.Create(ctx, userID, req)
+}
+
+func (c *ApiKeyController) List(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	return c.apiKeyService.List(ctx, userID)
+}
+
+func (c *ApiKeyController) Revoke(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid api key id"))
+	}
+
+	return c.apiKeyService.Revoke(ctx, userID, id)
+}
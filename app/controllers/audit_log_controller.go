@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditLogController struct {
+	auditLogService services.IAuditLogService
+}
+
+func NewAuditLogController() *AuditLogController {
+	return &AuditLogController{
+		auditLogService: services.NewAuditLogService(),
+	}
+}
+
+func (c *AuditLogController) GetAll(ctx *fiber.Ctx) error {
+	req, err := parseAuditLogFilter(ctx)
+	if err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.auditLogService.GetAll(ctx, req)
+}
+
+func (c *AuditLogController) Timeline(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid entity id"))
+	}
+
+	req, err := parseAuditLogFilter(ctx)
+	if err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.auditLogService.Timeline(ctx, id, req)
+}
+
+func (c *AuditLogController) Export(ctx *fiber.Ctx) error {
+	req, err := parseAuditLogFilter(ctx)
+	if err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.auditLogService.Export(ctx, req)
+}
+
+func (c *AuditLogController) VerifyChain(ctx *fiber.Ctx) error {
+	var req dto.AuditChainVerifyRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.auditLogService.VerifyChain(ctx, req)
+}
+
+// parseAuditLogFilter reads the regular filters plus any number of
+// details.<key>=<value> parameters matched against the details JSON.
+func parseAuditLogFilter(ctx *fiber.Ctx) (dto.AuditLogFilterRequest, error) {
+	var req dto.AuditLogFilterRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return req, err
+	}
+
+	ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		name, ok := strings.CutPrefix(string(key), "details.")
+		if !ok {
+			return
+		}
+
+		if req.Details == nil {
+			req.Details = map[string]string{}
+		}
+		req.Details[name] = string(value)
+	})
+
+	return req, nil
+}
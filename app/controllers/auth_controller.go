@@ -54,4 +54,8 @@ func (c *AuthController) RefreshToken(ctx *fiber.Ctx) error {
 	utils.Logger.Info("AUTH REFRESH TOKEN")
 	
 	return c.authService.RefreshToken(ctx, userID)
+}
+
+func (c *AuthController) Jwks(ctx *fiber.Ctx) error {
+	return c.authService.Jwks(ctx)
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type DataPrivacyController struct {
+	dataPrivacyService services.IDataPrivacyService
+}
+
+func NewDataPrivacyController() *DataPrivacyController {
+	return &DataPrivacyController{
+		dataPrivacyService: services.NewDataPrivacyService(),
+	}
+}
+
+func (c *DataPrivacyController) RequestExport(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	return c.dataPrivacyService.RequestExport(ctx, userID)
+}
+
+func (c *DataPrivacyController) GetExport(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid data export id"))
+	}
+
+	return c.dataPrivacyService.GetExport(ctx, userID, id)
+}
+
+func (c *DataPrivacyController) DownloadExport(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid data export id"))
+	}
+
+	return c.dataPrivacyService.DownloadExport(ctx, userID, id)
+}
+
+func (c *DataPrivacyController) EraseMe(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.EraseAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.dataPrivacyService.EraseMe(ctx, userID, req)
+}
+
+func (c *DataPrivacyController) Erase(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	return c.dataPrivacyService.Erase(ctx, id)
+}
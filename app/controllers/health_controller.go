@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"backend-path/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthController struct {
+	healthService services.IHealthService
+}
+
+func NewHealthController() *HealthController {
+	return &HealthController{
+		healthService: services.NewHealthService(),
+	}
+}
+
+func (c *HealthController) Live(ctx *fiber.Ctx) error {
+	return c.healthService.Live(ctx)
+}
+
+func (c *HealthController) Ready(ctx *fiber.Ctx) error {
+	return c.healthService.Ready(ctx)
+}
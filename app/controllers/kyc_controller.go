@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KycController struct {
+	kycService services.IKycService
+}
+
+func NewKycController() *KycController {
+	return &KycController{
+		kycService: services.NewKycService(),
+	}
+}
+
+func (c *KycController) Submit(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.KycSubmitRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	document, _ := ctx.FormFile("document")
+
+	return c.kycService.Submit(ctx, userID, req, document)
+}
+
+func (c *KycController) GetMine(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	return c.kycService.GetMine(ctx, userID)
+}
+
+func (c *KycController) GetAll(ctx *fiber.Ctx) error {
+	return c.kycService.GetAll(ctx, ctx.Query("status"))
+}
+
+func (c *KycController) GetByID(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid kyc submission id"))
+	}
+
+	return c.kycService.GetByID(ctx, id)
+}
+
+func (c *KycController) Document(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid kyc submission id"))
+	}
+
+	return c.kycService.Document(ctx, id)
+}
+
+func (c *KycController) Approve(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid kyc submission id"))
+	}
+
+	var req dto.KycReviewRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return utils.JsonError(ctx, err, "E_PARSE")
+		}
+	}
+
+	return c.kycService.Approve(ctx, id, req)
+}
+
+func (c *KycController) Reject(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid kyc submission id"))
+	}
+
+	var req dto.KycRejectRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.kycService.Reject(ctx, id, req)
+}
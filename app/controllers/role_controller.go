@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleController struct {
+	roleService services.IRoleService
+}
+
+func NewRoleController() *RoleController {
+	return &RoleController{
+		roleService: services.NewRoleService(),
+	}
+}
+
+func (c *RoleController) GetAll(ctx *fiber.Ctx) error {
+	return c.roleService.GetAll(ctx)
+}
+
+func (c *RoleController) GetByID(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid role id"))
+	}
+
+	return c.roleService.GetByID(ctx, models.Role(id))
+}
+
+func (c *RoleController) Create(ctx *fiber.Ctx) error {
+	var req dto.CreateRoleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.roleService.Create(ctx, req)
+}
+
+func (c *RoleController) Update(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid role id"))
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.roleService.Update(ctx, models.Role(id), req)
+}
+
+func (c *RoleController) Delete(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid role id"))
+	}
+
+	return c.roleService.Delete(ctx, models.Role(id))
+}
+
+func (c *RoleController) GetPermissions(ctx *fiber.Ctx) error {
+	return c.roleService.GetPermissions(ctx)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionController struct {
+	sessionService services.ISessionService
+}
+
+func NewSessionController() *SessionController {
+	return &SessionController{
+		sessionService: services.NewSessionService(),
+	}
+}
+
+func (c *SessionController) List(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	return c.sessionService.List(ctx, userID)
+}
+
+func (c *SessionController) Revoke(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid session id"))
+	}
+
+	return c.sessionService.Revoke(ctx, userID, id)
+}
@@ -95,5 +95,10 @@ func (c *TransactionController) GetHistory(ctx *fiber.Ctx) error {
 }
 
 func (c *TransactionController) GetStats(ctx *fiber.Ctx) error {
-	return c.transactionService.GetStats(ctx)
+	var req dto.TransactionStatsRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.transactionService.GetStats(ctx, req)
 }
\ No newline at end of file
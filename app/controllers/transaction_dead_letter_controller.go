@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"backend-path/app/services"
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TransactionDeadLetterController struct {
+	deadLetterService services.ITransactionDeadLetterService
+}
+
+func NewTransactionDeadLetterController() *TransactionDeadLetterController {
+	return &TransactionDeadLetterController{
+		deadLetterService: services.NewTransactionDeadLetterService(),
+	}
+}
+
+func (c *TransactionDeadLetterController) GetAll(ctx *fiber.Ctx) error {
+	return c.deadLetterService.GetAll(ctx, ctx.Query("status"))
+}
+
+func (c *TransactionDeadLetterController) GetByID(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid dead letter id"))
+	}
+
+	return c.deadLetterService.GetByID(ctx, id)
+}
+
+func (c *TransactionDeadLetterController) Replay(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid dead letter id"))
+	}
+
+	return c.deadLetterService.Replay(ctx, id)
+}
+
+func (c *TransactionDeadLetterController) Discard(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid dead letter id"))
+	}
+
+	return c.deadLetterService.Discard(ctx, id)
+}
@@ -21,7 +21,12 @@ func NewUserController() *UserController {
 }
 
 func (c *UserController) GetAll(ctx *fiber.Ctx) error {
-	return c.userService.GetAll(ctx)
+	var req dto.UserFilterRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.GetAll(ctx, req)
 }
 
 func (c *UserController) GetByID(ctx *fiber.Ctx) error {
@@ -58,6 +63,66 @@ func (c *UserController) Delete(ctx *fiber.Ctx) error {
 	return c.userService.Delete(ctx, id)
 }
 
+func (c *UserController) Restore(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	return c.userService.Restore(ctx, id)
+}
+
+func (c *UserController) Unlock(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	return c.userService.Unlock(ctx, id)
+}
+
+func (c *UserController) Freeze(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.ChangeAccountStatusRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.Freeze(ctx, id, req)
+}
+
+func (c *UserController) Suspend(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.ChangeAccountStatusRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.Suspend(ctx, id, req)
+}
+
+func (c *UserController) Reactivate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.ReactivateAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.Reactivate(ctx, id, req)
+}
+
 func (c *UserController) GetMe(ctx *fiber.Ctx) error {
 	userIDStr := ctx.Locals("user_auth").(string)
 
@@ -67,4 +132,52 @@ func (c *UserController) GetMe(ctx *fiber.Ctx) error {
 	}
 
 	return c.userService.GetByID(ctx, userID)	
+}
+
+func (c *UserController) UpdateMe(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.UpdateMe(ctx, userID, req)
+}
+
+func (c *UserController) VerifyEmail(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.VerifyEmailRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.VerifyEmail(ctx, userID, req)
+}
+
+func (c *UserController) CloseMe(ctx *fiber.Ctx) error {
+	userIDStr := ctx.Locals("user_auth").(string)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	var req dto.CloseAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return utils.JsonError(ctx, err, "E_PARSE")
+	}
+
+	return c.userService.Close(ctx, userID, req)
 }
\ No newline at end of file
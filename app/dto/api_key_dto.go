@@ -0,0 +1,25 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateApiKeyRequest struct {
+	Name          string   `json:"name" validate:"required,min=3,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=transactions:read transactions:write balances:read"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type ApiKeyResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	Scopes     []string  `json:"scopes"`
+	LastUsedAt *string   `json:"last_used_at"`
+	ExpiresAt  *string   `json:"expires_at"`
+	RevokedAt  *string   `json:"revoked_at"`
+	CreatedAt  string    `json:"created_at"`
+}
+
+type ApiKeyCreatedResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogFilterRequest struct {
+	EntityType string            `query:"entity_type" validate:"omitempty,max=30"`
+	EntityID   string            `query:"entity_id" validate:"omitempty,uuid"`
+	Action     string            `query:"action" validate:"omitempty,max=30"`
+	ActorID    string            `query:"actor_id" validate:"omitempty,uuid"`
+	RequestID  string            `query:"request_id" validate:"omitempty,max=100"`
+	From       string            `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string            `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Cursor     string            `query:"cursor" validate:"omitempty,max=200"`
+	Limit      int               `query:"limit" validate:"omitempty,min=1,max=200"`
+	Details    map[string]string `query:"-" validate:"max=5,dive,keys,min=1,max=50,endkeys,max=200"`
+}
+
+type AuditLogResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    uuid.UUID       `json:"entity_id"`
+	Action      string          `json:"action"`
+	Details     json.RawMessage `json:"details"`
+	Changes     json.RawMessage `json:"changes,omitempty"`
+	ActorUserID *uuid.UUID      `json:"actor_user_id,omitempty"`
+	ActorRole   string          `json:"actor_role,omitempty"`
+	RequestID   string          `json:"request_id,omitempty"`
+	TraceID     string          `json:"trace_id,omitempty"`
+	IP          string          `json:"ip,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	Seq         int64           `json:"seq"`
+	Hash        string          `json:"hash,omitempty"`
+	Redacted    bool            `json:"redacted"`
+}
+
+type AuditLogPageResponse struct {
+	Data       []AuditLogResponse `json:"data"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type AuditChainVerifyRequest struct {
+	EntityType string `query:"entity_type" validate:"omitempty,max=30"`
+	EntityID   string `query:"entity_id" validate:"omitempty,uuid"`
+}
+
+type AuditChainBreak struct {
+	EntityType string     `json:"entity_type"`
+	EntityID   uuid.UUID  `json:"entity_id"`
+	Seq        int64      `json:"seq"`
+	EntryID    *uuid.UUID `json:"entry_id,omitempty"`
+	Reason     string     `json:"reason"`
+}
+
+type AuditChainReport struct {
+	Valid    bool             `json:"valid"`
+	Chains   int              `json:"chains"`
+	Entries  int              `json:"entries"`
+	Redacted int              `json:"redacted"`
+	Unsealed int              `json:"unsealed"`
+	Break    *AuditChainBreak `json:"break,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/google/uuid"
+
+type EraseAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type DataExportResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status"`
+	Size        int64     `json:"size,omitempty"`
+	CompletedAt *string   `json:"completed_at,omitempty"`
+	ExpiresAt   string    `json:"expires_at"`
+	CreatedAt   string    `json:"created_at"`
+}
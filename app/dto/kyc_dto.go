@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/google/uuid"
+
+type KycSubmitRequest struct {
+	RequestedTier  string `form:"requested_tier" validate:"required,oneof=verified enhanced"`
+	FullName       string `form:"full_name" validate:"required,min=2,max=150"`
+	DateOfBirth    string `form:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Country        string `form:"country" validate:"required,iso3166_1_alpha2"`
+	DocumentType   string `form:"document_type" validate:"required,oneof=passport id_card driving_license"`
+	DocumentNumber string `form:"document_number" validate:"required,min=3,max=50,alphanum"`
+}
+
+type KycReviewRequest struct {
+	Note string `json:"note" validate:"omitempty,max=500"`
+}
+
+type KycRejectRequest struct {
+	Note string `json:"note" validate:"required,max=500"`
+}
+
+type KycSubmissionResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Username       string     `json:"username,omitempty"`
+	RequestedTier  string     `json:"requested_tier"`
+	Status         string     `json:"status"`
+	FullName       string     `json:"full_name"`
+	DateOfBirth    string     `json:"date_of_birth"`
+	Country        string     `json:"country"`
+	DocumentType   string     `json:"document_type"`
+	DocumentNumber string     `json:"document_number"`
+	ReviewerID     *uuid.UUID `json:"reviewer_id,omitempty"`
+	ReviewNote     *string    `json:"review_note,omitempty"`
+	ReviewedAt     *string    `json:"reviewed_at,omitempty"`
+	CreatedAt      string     `json:"created_at"`
+}
+
+type KycPolicyResponse struct {
+	AllowedTypes       []string `json:"allowed_types"`
+	MaxAmount          float64  `json:"max_amount"`
+	DailyOutgoingLimit float64  `json:"daily_outgoing_limit"`
+}
+
+type KycStatusResponse struct {
+	Tier        string                  `json:"tier"`
+	Policy      KycPolicyResponse       `json:"policy"`
+	Submissions []KycSubmissionResponse `json:"submissions"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/google/uuid"
+
+type SessionResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Device         string    `json:"device"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"user_agent"`
+	Current        bool      `json:"current"`
+	LastActivityAt string    `json:"last_activity_at"`
+	ExpiresAt      string    `json:"expires_at"`
+	CreatedAt      string    `json:"created_at"`
+}
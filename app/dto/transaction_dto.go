@@ -4,15 +4,18 @@ import "github.com/google/uuid"
 
 type CreditRequest struct {
 	Amount float64 `json:"amount" validate:"required,gt=0,max=1000000"`
+	CallbackURL string `json:"callback_url" validate:"omitempty,url,max=2048"`
 }
 
 type DebitRequest struct {
 	Amount float64 `json:"amount" validate:"required,gt=0,max=1000000"`
+	CallbackURL string `json:"callback_url" validate:"omitempty,url,max=2048"`
 }
 
 type TransferRequest struct {
 	ToUserID uuid.UUID  `json:"to_user_id" validate:"required,uuid"`
 	Amount   float64 `json:"amount" validate:"required,gt=0,max=1000000"`
+	CallbackURL string `json:"callback_url" validate:"omitempty,url,max=2048"`
 }
 
 type TransactionResponse struct {
@@ -22,15 +25,62 @@ type TransactionResponse struct {
 	Amount     float64   `json:"amount"`
 	Type       string    `json:"type"`
 	Status     string    `json:"status"`
+	FailureReason *string `json:"failure_reason,omitempty"`
 	CreatedAt  string    `json:"created_at"`
 }
 
+type TransactionStatsRequest struct {
+	From     string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Type     string `query:"type" validate:"omitempty,oneof=deposit withdraw transfer"`
+	Interval string `query:"interval" validate:"omitempty,oneof=hour day"`
+}
+
+// TransactionStatsResponse aggregates every instance's transactions from
+// the database. Queue reflects only the instance that answered.
 type TransactionStatsResponse struct {
-	TotalProcessed   int64   `json:"total_processed"`
-	TotalSuccessful  int64   `json:"total_successful"`
-	TotalFailed      int64   `json:"total_failed"`
-	PendingInQueue   int     `json:"pending_in_queue"`
-	TotalCredited    float64 `json:"total_credited"`
-	TotalDebited     float64 `json:"total_debited"`
-	TotalTransferred float64 `json:"total_transferred"`
-}
\ No newline at end of file
+	From             string                           `json:"from"`
+	To               string                           `json:"to"`
+	Interval         string                           `json:"interval"`
+	TotalProcessed   int64                            `json:"total_processed"`
+	TotalSuccessful  int64                            `json:"total_successful"`
+	TotalFailed      int64                            `json:"total_failed"`
+	TotalCredited    float64                          `json:"total_credited"`
+	TotalDebited     float64                          `json:"total_debited"`
+	TotalTransferred float64                          `json:"total_transferred"`
+	Buckets          []TransactionStatsBucketResponse `json:"buckets"`
+	Queue            TransactionQueueStatsResponse    `json:"queue"`
+}
+
+type TransactionStatsBucketResponse struct {
+	Bucket string  `json:"bucket"`
+	Type   string  `json:"type"`
+	Status string  `json:"status"`
+	Count  int64   `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+type TransactionQueueStatsResponse struct {
+	InstanceID       string `json:"instance_id"`
+	PendingInQueue   int    `json:"pending_in_queue"`
+	ShardQueueDepths []int  `json:"shard_queue_depths"`
+}
+
+type TransactionDeadLetterResponse struct {
+	ID                    uuid.UUID  `json:"id"`
+	JobID                 uuid.UUID  `json:"job_id"`
+	Type                  string     `json:"type"`
+	FromUserID            *uuid.UUID `json:"from_user_id,omitempty"`
+	ToUserID              *uuid.UUID `json:"to_user_id,omitempty"`
+	Amount                float64    `json:"amount"`
+	Async                 bool       `json:"async"`
+	Error                 string     `json:"error"`
+	Attempts              int        `json:"attempts"`
+	Status                string     `json:"status"`
+	RequestID             string     `json:"request_id,omitempty"`
+	ReplayedTransactionID *uuid.UUID `json:"replayed_transaction_id,omitempty"`
+	ResolvedBy            *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolvedAt            *string    `json:"resolved_at,omitempty"`
+	SubmittedAt           string     `json:"submitted_at"`
+	CreatedAt             string     `json:"created_at"`
+}
@@ -1,19 +1,62 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UpdateUserRequest struct {
 	Username string `json:"username" validate:"omitempty,min=3,max=50,alphanum"`
 	Email    string `json:"email" validate:"omitempty,email,max=100"`
-	Role   	 string `json:"role" validate:"omitempty,oneof=user admin mod"`
+	Role   	 string `json:"role" validate:"omitempty,min=2,max=50"`
+}
+
+type UpdateProfileRequest struct {
+	Username        string `json:"username" validate:"omitempty,min=3,max=50,alphanum"`
+	Email           string `json:"email" validate:"omitempty,email,max=100"`
+	CurrentPassword string `json:"current_password" validate:"required_with=Email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,len=64,hexadecimal"`
+}
+
+type CloseAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+	Reason   string `json:"reason" validate:"omitempty,max=255"`
+}
+
+type ChangeAccountStatusRequest struct {
+	Reason    string     `json:"reason" validate:"required,max=255"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
+
+type ReactivateAccountRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+type UserFilterRequest struct {
+	Role        string `query:"role" validate:"omitempty,min=2,max=50"`
+	Status      string `query:"status" validate:"omitempty,oneof=active closed frozen suspended"`
+	CreatedFrom string `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	Q           string `query:"q" validate:"omitempty,min=2,max=100"`
+	Sort        string `query:"sort" validate:"omitempty,oneof=created_at updated_at username email"`
+	Order       string `query:"order" validate:"omitempty,oneof=asc desc"`
 }
 
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt string    `json:"created_at"`
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	PendingEmail *string   `json:"pending_email,omitempty"`
+	Role         string    `json:"role"`
+	Status       string    `json:"status"`
+	KycTier      string    `json:"kyc_tier"`
+	StatusReason    *string   `json:"status_reason,omitempty"`
+	StatusExpiresAt *string   `json:"status_expires_at,omitempty"`
+	CreatedAt    string    `json:"created_at"`
 }
 
 type UserListResponse struct {
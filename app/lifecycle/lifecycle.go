@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"backend-path/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phase orders the shutdown steps. Every step of a phase finishes before
+// the next phase starts, steps of one phase run in registration order.
+type Phase int
+
+const (
+	// PhaseIngress stops taking requests
+	PhaseIngress Phase = iota
+	// PhaseWorkers stops background work and drains the transaction queue
+	PhaseWorkers
+	// PhaseFlush writes out what is still buffered, audit events and traces
+	PhaseFlush
+	// PhaseClose closes Redis and the database
+	PhaseClose
+	phaseCount
+)
+
+func (p Phase) String() string {
+	return [...]string{"ingress", "workers", "flush", "close"}[p]
+}
+
+type hook struct {
+	name string
+	stop func() error
+}
+
+var (
+	ready        atomic.Bool
+	mu           sync.Mutex
+	hooks        [phaseCount][]hook
+	shutdownOnce sync.Once
+	shuttingDown = make(chan struct{})
+)
+
+// SetReady marks the instance able to take traffic. It is ignored once a
+// shutdown started.
+func SetReady() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	select {
+	case <-shuttingDown:
+		return
+	default:
+	}
+	ready.Store(true)
+}
+
+// IsReady reports whether the readiness probe should pass.
+func IsReady() bool {
+	return ready.Load()
+}
+
+// OnShutdown registers a step to run in the given phase of Shutdown.
+func OnShutdown(phase Phase, name string, stop func() error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	hooks[phase] = append(hooks[phase], hook{name: name, stop: stop})
+}
+
+// Shutdown flips readiness off, waits readinessDelay so load balancers stop
+// routing to the instance, then runs the registered steps phase by phase.
+// A failing step is logged and does not stop the ones after it. Only the
+// first call does anything.
+func Shutdown(readinessDelay time.Duration) {
+	shutdownOnce.Do(func() {
+		mu.Lock()
+		ready.Store(false)
+		close(shuttingDown)
+		registered := hooks
+		mu.Unlock()
+
+		utils.Logger.Info("Instance marked not ready, draining in " + readinessDelay.String())
+		time.Sleep(readinessDelay)
+
+		for phase := Phase(0); phase < phaseCount; phase++ {
+			for _, h := range registered[phase] {
+				startTime := time.Now()
+				if err := h.stop(); err != nil {
+					utils.Logger.Error("❌ SHUTDOWN " + phase.String() + "/" + h.name + " ERROR: " + err.Error())
+					continue
+				}
+				utils.Logger.Info("Shutdown " + phase.String() + "/" + h.name + " done in " + time.Since(startTime).String())
+			}
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"backend-path/utils"
+
+	"go.uber.org/zap"
+)
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// LogMailer writes outgoing mail to the application log. It stands in until
+// an SMTP or provider backed mailer is configured.
+type LogMailer struct{}
+
+func NewMailer() Mailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	utils.Logger.Info("📧 MAIL",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("body", body),
+	)
+	return nil
+}
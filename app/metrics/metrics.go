@@ -11,14 +11,29 @@ var (
 	once     sync.Once
 	Registry *prometheus.Registry
 
-	HttpRequestsTotal     *prometheus.CounterVec
-	HttpRequestDuration   *prometheus.HistogramVec
-	HttpRequestsInFlight  prometheus.Gauge
-	TransactionsTotal     *prometheus.CounterVec
-	TransactionAmount     *prometheus.HistogramVec
-	ActiveUsers           prometheus.Gauge
-	DatabaseQueriesTotal  *prometheus.CounterVec
-	DatabaseQueryDuration *prometheus.HistogramVec
+	HttpRequestsTotal             *prometheus.CounterVec
+	HttpRequestDuration           *prometheus.HistogramVec
+	HttpRequestsInFlight          prometheus.Gauge
+	TransactionsTotal             *prometheus.CounterVec
+	TransactionAmount             *prometheus.HistogramVec
+	ActiveUsers                   prometheus.Gauge
+	DatabaseQueriesTotal          *prometheus.CounterVec
+	DatabaseQueryDuration         *prometheus.HistogramVec
+	LoginFailuresTotal            *prometheus.CounterVec
+	AccountLockoutsTotal          prometheus.Counter
+	AuditEventsQueuedTotal        prometheus.Counter
+	AuditEventsDroppedTotal       prometheus.Counter
+	AuditOutboxQueueDepth         prometheus.Gauge
+	TransactionShardQueueDepth    *prometheus.GaugeVec
+	TransactionShardJobsTotal     *prometheus.CounterVec
+	TransactionShardJobDuration   *prometheus.HistogramVec
+	TransactionQueueDepth         prometheus.Gauge
+	TransactionQueueWaitDuration  prometheus.Histogram
+	TransactionProcessingDuration prometheus.Histogram
+	TransactionQueueRejectedTotal prometheus.Counter
+	TransactionRetriesTotal       *prometheus.CounterVec
+	TransactionDeadLetteredTotal  prometheus.Counter
+	TransactionDeadLetterBacklog  prometheus.Gauge
 )
 
 func Init() {
@@ -93,6 +108,119 @@ func Init() {
 			[]string{"operation", "table"},
 		)
 
+		LoginFailuresTotal = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "auth_login_failures_total",
+				Help: "Total number of failed login attempts",
+			},
+			[]string{"reason"},
+		)
+
+		AccountLockoutsTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "auth_account_lockouts_total",
+				Help: "Total number of temporary account lockouts",
+			},
+		)
+
+		AuditEventsQueuedTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "audit_events_queued_total",
+				Help: "Total number of audit events written to the audit outbox",
+			},
+		)
+
+		AuditEventsDroppedTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "audit_events_dropped_total",
+				Help: "Total number of audit events that could not be persisted",
+			},
+		)
+
+		AuditOutboxQueueDepth = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "audit_outbox_queue_depth",
+				Help: "Number of audit events waiting in the audit outbox",
+			},
+		)
+
+		TransactionShardQueueDepth = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "transaction_shard_queue_depth",
+				Help: "Number of jobs waiting in a transaction worker shard",
+			},
+			[]string{"shard"},
+		)
+
+		TransactionShardJobsTotal = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "transaction_shard_jobs_total",
+				Help: "Total number of jobs processed by a transaction worker shard",
+			},
+			[]string{"shard", "status"},
+		)
+
+		TransactionShardJobDuration = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "transaction_shard_job_duration_seconds",
+				Help:    "Time a transaction worker shard spends on a job, including waiting for the other shard of a transfer",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"shard"},
+		)
+
+		TransactionQueueDepth = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "transaction_queue_depth",
+				Help: "Number of transaction jobs queued and not processed yet",
+			},
+		)
+
+		TransactionQueueWaitDuration = prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "transaction_queue_wait_seconds",
+				Help:    "Time a transaction job waits in the queue before a worker picks it up",
+				Buckets: prometheus.DefBuckets,
+			},
+		)
+
+		TransactionProcessingDuration = prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "transaction_processing_seconds",
+				Help:    "Time spent processing a transaction job",
+				Buckets: prometheus.DefBuckets,
+			},
+		)
+
+		TransactionQueueRejectedTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "transaction_queue_rejected_total",
+				Help: "Total number of transaction jobs rejected because the queue was full",
+			},
+		)
+
+		TransactionRetriesTotal = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "transaction_retries_total",
+				Help: "Total number of transaction processing retries by cause",
+			},
+			[]string{"cause"},
+		)
+
+		TransactionDeadLetteredTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "transaction_dead_lettered_total",
+				Help: "Total number of transaction jobs moved to the dead-letter table",
+			},
+		)
+
+		TransactionDeadLetterBacklog = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "transaction_dead_letter_backlog",
+				Help: "Number of dead-lettered transaction jobs waiting for a replay or discard",
+			},
+		)
+
 		Registry.MustRegister(
 			HttpRequestsTotal,
 			HttpRequestDuration,
@@ -102,6 +230,21 @@ func Init() {
 			ActiveUsers,
 			DatabaseQueriesTotal,
 			DatabaseQueryDuration,
+			LoginFailuresTotal,
+			AccountLockoutsTotal,
+			AuditEventsQueuedTotal,
+			AuditEventsDroppedTotal,
+			AuditOutboxQueueDepth,
+			TransactionShardQueueDepth,
+			TransactionShardJobsTotal,
+			TransactionShardJobDuration,
+			TransactionQueueDepth,
+			TransactionQueueWaitDuration,
+			TransactionProcessingDuration,
+			TransactionQueueRejectedTotal,
+			TransactionRetriesTotal,
+			TransactionDeadLetteredTotal,
+			TransactionDeadLetterBacklog,
 		)
 	})
 }
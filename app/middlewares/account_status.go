@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/configs"
+	"backend-path/constants"
+	"backend-path/utils"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const accountStatusCacheTTL = 5 * time.Minute
+
+// checkAccountStatus rejects users that are suspended or closed. The effective
+// status is cached briefly, never past the expiry of a timed suspension.
+func checkAccountStatus(userID string) error {
+	status := cachedAccountStatus(userID)
+	if status == 0 {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return constants.ErrAccountSuspended
+		}
+
+		user, err := repository.NewUserRepository().FindByID(id)
+		if err != nil {
+			return ErrSessionRevoked
+		}
+
+		status = user.EffectiveStatus()
+		ttl := accountStatusCacheTTL
+		if user.StatusExpiresAt != nil && status != models.UserStatusActive && time.Until(*user.StatusExpiresAt) < ttl {
+			ttl = time.Until(*user.StatusExpiresAt)
+		}
+		setAccountStatusCache(userID, status, ttl)
+	}
+
+	switch status {
+	case models.UserStatusSuspended:
+		return constants.ErrAccountSuspended
+	case models.UserStatusClosed:
+		return constants.ErrAccountClosed
+	}
+
+	return nil
+}
+
+func cachedAccountStatus(userID string) models.UserStatus {
+	if configs.RedisStorage == nil {
+		return 0
+	}
+
+	data, err := configs.RedisStorage.Get(KeyAccountStatusCache(userID))
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+
+	status, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0
+	}
+
+	return models.UserStatus(status)
+}
+
+func setAccountStatusCache(userID string, status models.UserStatus, ttl time.Duration) {
+	if configs.RedisStorage == nil || ttl <= 0 {
+		return
+	}
+
+	cacheKey := KeyAccountStatusCache(userID)
+	if err := configs.RedisStorage.Set(cacheKey, []byte(strconv.Itoa(int(status))), ttl); err != nil {
+		utils.Logger.Error("❌ REDIS KEY " + cacheKey + " ERROR: " + err.Error())
+	}
+}
+
+func KeyAccountStatusCache(userID string) string {
+	return constants.CacheAccountStatus + "_" + userID
+}
+
+func InvalidateAccountStatusCache(userID string) {
+	if configs.RedisStorage == nil {
+		return
+	}
+
+	configs.RedisStorage.Delete(KeyAccountStatusCache(userID))
+}
@@ -32,7 +32,7 @@ func SetupCors() fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     strings.Join(origins, ","),
 		AllowMethods:     "GET, POST, PUT, DELETE, PATCH, OPTIONS, HEAD",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With, X-API-Key",
 		ExposeHeaders:    "Content-Length, Content-Type",
 		AllowCredentials: allowCredentials,
 		MaxAge:           86400,
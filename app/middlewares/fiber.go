@@ -2,8 +2,10 @@ package middlewares
 
 import (
 	"backend-path/app/metrics"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -38,12 +40,89 @@ func Setup(app *fiber.App) {
 
 	app.Use(RequestTracker())
 
+	app.Use(RequestBodyLimit())
+
 	debug, _ := strconv.ParseBool(os.Getenv("APP_DEBUG"))
 	if debug {
 		app.Use(pprof.New())
 	}
 }
 
+// uploadRoutes accept multipart uploads larger than APP_MAX_BODY, up to the
+// limit registered with AllowUpload. Their handlers validate the size of
+// each file.
+var uploadRoutes = map[string]int{}
+
+// AllowUpload lets multipart bodies of up to maxBytes through to the route.
+// It has to be called before the server starts.
+func AllowUpload(method, path string, maxBytes int) {
+	uploadRoutes[method+" "+strings.ToLower(path)] = maxBytes
+}
+
+// RequestBodyLimit applies APP_MAX_BODY (in KB) to every request except
+// multipart uploads to one of the uploadRoutes. The server streams bodies
+// over APP_MAX_BODY instead of reading them, so the declared length is
+// checked before anything reads the body, and a chunked body is read only
+// up to the limit.
+func RequestBodyLimit() func(*fiber.Ctx) error {
+	maxBody, _ := strconv.Atoi(os.Getenv("APP_MAX_BODY"))
+	if maxBody == 0 {
+		maxBody = 4
+	}
+	size := maxBody * 1024
+
+	return func(c *fiber.Ctx) error {
+		limit := size
+		if uploadLimit, ok := uploadRequestLimit(c); ok {
+			limit = uploadLimit
+		}
+
+		contentLength := c.Request().Header.ContentLength()
+		if contentLength > limit {
+			return bodyTooLarge(c)
+		}
+
+		if stream := c.Context().RequestBodyStream(); contentLength == -1 && stream != nil {
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   true,
+					"message": "Invalid request body",
+				})
+			}
+
+			if len(body) > limit {
+				return bodyTooLarge(c)
+			}
+			c.Request().SetBody(body)
+		}
+
+		return c.Next()
+	}
+}
+
+// bodyTooLarge closes the connection, the unread rest of the body would
+// otherwise be taken for the next request.
+func bodyTooLarge(c *fiber.Ctx) error {
+	c.Context().SetConnectionClose()
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+		"error":   true,
+		"message": "Request body too large",
+	})
+}
+
+// uploadRequestLimit matches paths the way the router does, ignoring case
+// and a trailing slash.
+func uploadRequestLimit(c *fiber.Ctx) (int, bool) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return 0, false
+	}
+
+	path := strings.ToLower(strings.TrimSuffix(c.Path(), "/"))
+	limit, ok := uploadRoutes[c.Method()+" "+path]
+	return limit, ok
+}
+
 func MaxBodySize(sizeInMb int) func(*fiber.Ctx) error {
 	size := sizeInMb * 1024 * 1024
 	return func(c *fiber.Ctx) error {
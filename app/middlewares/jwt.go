@@ -3,20 +3,26 @@ package middlewares
 import (
 	"backend-path/app/metrics"
 	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/configs"
+	"backend-path/constants"
 	"backend-path/utils"
 	"errors"
-	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 type JwtCustomClaims struct {
-	Issuer string `json:"issuer"`
-	Role models.Role `json:"role"`
+	Role      models.Role `json:"role"`
+	SessionID string      `json:"sid"`
+	// LegacySubject is the user id in tokens issued before the standard
+	// claims were used, see legacyTokenAuth.
+	LegacySubject string `json:"issuer,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -38,6 +44,11 @@ func JwtMiddleware(ctx *fiber.Ctx) error {
 		}
 	}
 
+	// api key authentication for server-to-server clients
+	if apiKey := ctx.Get(constants.ApiKeyHeader); apiKey != "" {
+		return apiKeyAuth(ctx, apiKey)
+	}
+
 	// check header token
 	authorizationToken := getAuthorizationToken(ctx)
 	if authorizationToken == "" {
@@ -46,43 +57,131 @@ func JwtMiddleware(ctx *fiber.Ctx) error {
 	}
 
 	// verify token
-	jwtToken, err := jwt.ParseWithClaims(authorizationToken, &JwtCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
-
+	claims := &JwtCustomClaims{}
+	jwtToken, err := jwt.ParseWithClaims(authorizationToken, claims, configs.JwtKeys.Keyfunc)
 	if jwtToken == nil {
 		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token"))
 	}
 
-	claims, ok := jwtToken.Claims.(*JwtCustomClaims)
-	if !ok {
-		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token claims"))
+	if _, ok := jwtToken.Header["kid"]; !ok {
+		return legacyTokenAuth(ctx, claims, err)
 	}
 
-	ctx.Locals("user_auth", claims.Issuer)
-	ctx.Locals("user_role", claims.Role)
+	if !claims.VerifyIssuer(configs.JwtKeys.Issuer, true) || !claims.VerifyAudience(configs.JwtKeys.Audience, true) {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token claims"))
+	}
 
-	trackActiveUser(claims.Issuer)
+	if claims.Subject == "" {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token subject"))
+	}
 
 	if err != nil {
-		if ve, ok := err.(*jwt.ValidationError); ok {
-			if ve.Errors&jwt.ValidationErrorExpired != 0 {
-				ctx.Locals("token_expired", true)
-				if ctx.Path() == "/api/v1/auth/refresh" {
-					utils.Logger.Info("⏰ Expired token - refresh allowed")
-					return ctx.Next()
-				}
-				return utils.JsonErrorUnauthorized(ctx, errors.New("token expired"))
-			}
+		ve, ok := err.(*jwt.ValidationError)
+		if !ok || ve.Errors&^jwt.ValidationErrorExpired != 0 {
+			return utils.JsonErrorUnauthorized(ctx, err)
+		}
+
+		ctx.Locals("token_expired", true)
+		if ctx.Path() != "/api/v1/auth/refresh" {
+			return utils.JsonErrorUnauthorized(ctx, errors.New("token expired"))
 		}
+		utils.Logger.Info("⏰ Expired token - refresh allowed")
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token session"))
+	}
+
+	if err := validateSession(sessionID, claims.Subject); err != nil {
 		return utils.JsonErrorUnauthorized(ctx, err)
 	}
 
+	if err := checkAccountStatus(claims.Subject); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
+	ctx.Locals("user_auth", claims.Subject)
+	ctx.Locals("user_role", claims.Role)
+	ctx.Locals("session_id", sessionID.String())
+
+	trackActiveUser(claims.Subject)
+	touchSession(sessionID)
 
 	utils.Logger.Info("✅ SET USER AUTH")
 	return ctx.Next()
 }
 
+// legacyTokenAuth accepts a token issued before tokens carried a kid, a
+// session and the standard claims, so upgrading does not sign everyone out.
+// Such tokens are no longer issued, they are accepted until they expire and
+// refreshing one starts a session. Revoking sessions does not reach them, so
+// the role is read from the user instead of the token.
+func legacyTokenAuth(ctx *fiber.Ctx, claims *JwtCustomClaims, err error) error {
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, err)
+	}
+
+	userID, err := uuid.Parse(claims.LegacySubject)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid token subject"))
+	}
+
+	if err := checkAccountStatus(claims.LegacySubject); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
+	user, err := repository.NewUserRepository().FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, ErrSessionRevoked)
+	}
+
+	ctx.Locals("user_auth", claims.LegacySubject)
+	ctx.Locals("user_role", user.RoleID)
+
+	trackActiveUser(claims.LegacySubject)
+
+	utils.Logger.Info("✅ SET USER AUTH (legacy token)")
+	return ctx.Next()
+}
+
+func apiKeyAuth(ctx *fiber.Ctx, key string) error {
+	prefix, ok := utils.ParseApiKeyPrefix(key)
+	if !ok {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid api key"))
+	}
+
+	apiKey, err := repository.NewApiKeyRepository().FindByPrefix(prefix)
+	if err != nil || !utils.CompareApiKeyHash(key, apiKey.KeyHash) {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid api key"))
+	}
+
+	if apiKey.IsRevoked() {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("api key revoked"))
+	}
+
+	if apiKey.IsExpired() {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("api key expired"))
+	}
+
+	if err := checkAccountStatus(apiKey.UserID.String()); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
+	ctx.Locals("user_auth", apiKey.UserID.String())
+	ctx.Locals("user_role", apiKey.User.RoleID)
+	ctx.Locals("api_key_id", apiKey.ID.String())
+	ctx.Locals("api_key_scopes", apiKey.ScopeList())
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > time.Minute {
+		go repository.NewApiKeyRepository().TouchLastUsed(apiKey.ID, now)
+	}
+
+	utils.Logger.Info("✅ SET API KEY AUTH")
+	return ctx.Next()
+}
+
 func getAuthorizationToken(ctx *fiber.Ctx) string {
 	authorizationToken := string(ctx.Request().Header.Peek("Authorization"))
 	return strings.Replace(authorizationToken, "Bearer ", "", 1)
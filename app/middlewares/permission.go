@@ -0,0 +1,159 @@
+package middlewares
+
+import (
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/configs"
+	"backend-path/constants"
+	"backend-path/utils"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RequirePermission(permission string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userRole, ok := ctx.Locals("user_role").(models.Role)
+		if !ok {
+			return utils.JsonErrorUnauthorized(ctx, errors.New("user role not found"))
+		}
+
+		permissions, err := resolvePermissions(ctx, userRole)
+		if err != nil {
+			return utils.JsonErrorInternal(ctx, err, "E_PERMISSION_RESOLVE")
+		}
+
+		if _, ok := permissions[permission]; !ok {
+			return utils.JsonErrorForbidden(ctx, errors.New("insufficient permissions"))
+		}
+
+		return ctx.Next()
+	}
+}
+
+func HasPermission(ctx *fiber.Ctx, permission string) bool {
+	userRole, ok := ctx.Locals("user_role").(models.Role)
+	if !ok {
+		return false
+	}
+
+	permissions, err := resolvePermissions(ctx, userRole)
+	if err != nil {
+		return false
+	}
+
+	_, ok = permissions[permission]
+	return ok
+}
+
+// resolvePermissions memoizes the permission set on the request and caches it
+// per role in redis, role updates drop the cached entry.
+func resolvePermissions(ctx *fiber.Ctx, role models.Role) (map[string]struct{}, error) {
+	if permissions, ok := ctx.Locals("user_permissions").(map[string]struct{}); ok {
+		return permissions, nil
+	}
+
+	names, err := rolePermissionNames(role)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		permissions[name] = struct{}{}
+	}
+
+	ctx.Locals("user_permissions", permissions)
+	return permissions, nil
+}
+
+// Outranks reports whether the role of the current user sits above the
+// given role: it holds every permission of that role and at least one more.
+// Roles are sets of permissions, so equal or unrelated roles do not outrank
+// each other.
+func Outranks(ctx *fiber.Ctx, other models.Role) (bool, error) {
+	userRole, ok := ctx.Locals("user_role").(models.Role)
+	if !ok {
+		return false, nil
+	}
+
+	own, err := resolvePermissions(ctx, userRole)
+	if err != nil {
+		return false, err
+	}
+
+	names, err := rolePermissionNames(other)
+	if err != nil {
+		return false, err
+	}
+
+	if len(names) >= len(own) {
+		return false, nil
+	}
+
+	for _, name := range names {
+		if _, ok := own[name]; !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func rolePermissionNames(role models.Role) ([]string, error) {
+	if names := getRolePermissionsCache(role); names != nil {
+		return names, nil
+	}
+
+	names, err := repository.NewRoleRepository().PermissionNames(role)
+	if err != nil {
+		return nil, err
+	}
+	setRolePermissionsCache(role, names)
+
+	return names, nil
+}
+
+func KeyRolePermissionsCache(role models.Role) string {
+	return constants.CacheRolePermissions + "_" + strconv.Itoa(int(role))
+}
+
+func getRolePermissionsCache(role models.Role) []string {
+	if configs.RedisStorage == nil {
+		return nil
+	}
+
+	data, err := configs.RedisStorage.Get(KeyRolePermissionsCache(role))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil
+	}
+
+	return names
+}
+
+func setRolePermissionsCache(role models.Role, names []string) {
+	if configs.RedisStorage == nil {
+		return
+	}
+
+	if names == nil {
+		names = []string{}
+	}
+
+	data, err := json.Marshal(names)
+	if err != nil {
+		return
+	}
+
+	if err := configs.RedisStorage.Set(KeyRolePermissionsCache(role), data, 10*time.Minute); err != nil {
+		utils.Logger.Error("❌ REDIS KEY " + KeyRolePermissionsCache(role) + " ERROR: " + err.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"backend-path/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope only restricts api key requests, token sessions keep the
+// full privileges of their user.
+func RequireScope(scope string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		scopes, ok := ctx.Locals("api_key_scopes").([]string)
+		if !ok {
+			return ctx.Next()
+		}
+
+		for _, s := range scopes {
+			if s == scope {
+				return ctx.Next()
+			}
+		}
+
+		return utils.JsonErrorForbidden(ctx, errors.New("api key is missing scope "+scope))
+	}
+}
+
+func DenyApiKey(ctx *fiber.Ctx) error {
+	if ctx.Locals("api_key_id") != nil {
+		return utils.JsonErrorForbidden(ctx, errors.New("api keys cannot access this resource"))
+	}
+
+	return ctx.Next()
+}
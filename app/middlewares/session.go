@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"backend-path/app/repository"
+	"backend-path/configs"
+	"backend-path/constants"
+	"backend-path/utils"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionRevoked = errors.New("session revoked or expired")
+
+	sessionTouchMap = make(map[uuid.UUID]time.Time)
+	sessionTouchMu  sync.Mutex
+)
+
+const (
+	sessionCacheTTL      = 5 * time.Minute
+	sessionTouchInterval = time.Minute
+)
+
+// validateSession checks that the token's session is still active. Active
+// sessions are cached briefly in redis, revocation drops the cache entry.
+func validateSession(sessionID uuid.UUID, userID string) error {
+	cacheKey := KeySessionCache(sessionID)
+	if configs.RedisStorage != nil {
+		if data, err := configs.RedisStorage.Get(cacheKey); err == nil && string(data) == userID {
+			return nil
+		}
+	}
+
+	session, err := repository.NewSessionRepository().FindByID(sessionID)
+	if err != nil || session.UserID.String() != userID || !session.IsActive() {
+		return ErrSessionRevoked
+	}
+
+	if configs.RedisStorage != nil {
+		ttl := time.Until(session.ExpiresAt)
+		if ttl > sessionCacheTTL {
+			ttl = sessionCacheTTL
+		}
+		if err := configs.RedisStorage.Set(cacheKey, []byte(userID), ttl); err != nil {
+			utils.Logger.Error("❌ REDIS KEY " + cacheKey + " ERROR: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// touchSession persists the session's last activity, at most once per
+// sessionTouchInterval per instance.
+func touchSession(sessionID uuid.UUID) {
+	sessionTouchMu.Lock()
+	now := time.Now()
+	if last, ok := sessionTouchMap[sessionID]; ok && now.Sub(last) < sessionTouchInterval {
+		sessionTouchMu.Unlock()
+		return
+	}
+
+	for id, last := range sessionTouchMap {
+		if now.Sub(last) > sessionCacheTTL {
+			delete(sessionTouchMap, id)
+		}
+	}
+	sessionTouchMap[sessionID] = now
+	sessionTouchMu.Unlock()
+
+	go repository.NewSessionRepository().TouchActivity(sessionID, now)
+}
+
+func KeySessionCache(sessionID uuid.UUID) string {
+	return constants.CacheSession + "_" + sessionID.String()
+}
+
+func InvalidateSessionCache(sessionIDs ...uuid.UUID) {
+	if configs.RedisStorage == nil {
+		return
+	}
+
+	for _, id := range sessionIDs {
+		configs.RedisStorage.Delete(KeySessionCache(id))
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogArchive is a compressed JSONL export of audit entries removed from
+// the database, stored on the blob store.
+type AuditLogArchive struct {
+	ID            uint64      `json:"id" gorm:"primaryKey"`
+	PartitionName string      `json:"partition_name" gorm:"type:varchar(63);not null"`
+	EntityType    *EntityType `json:"entity_type" gorm:"type:smallint"`
+	BlobKey       string      `json:"blob_key" gorm:"type:varchar(255);not null"`
+	RowCount      int64       `json:"row_count" gorm:"not null"`
+	Sha256        string      `json:"sha256" gorm:"column:sha256;type:varchar(64);not null"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+func (AuditLogArchive) TableName() string {
+	return "audit_log_archives"
+}
+
+// AuditChainArchiveHead is the latest archived entry of a chain. Entries up
+// to it are no longer in the database and verification continues from it.
+type AuditChainArchiveHead struct {
+	EntityType EntityType `json:"entity_type" gorm:"primaryKey;type:smallint"`
+	EntityID   uuid.UUID  `json:"entity_id" gorm:"primaryKey;type:uuid"`
+	Seq        int64      `json:"seq" gorm:"not null"`
+	Hash       string     `json:"hash" gorm:"type:varchar(64);not null"`
+	ArchivedAt time.Time  `json:"archived_at"`
+}
+
+func (AuditChainArchiveHead) TableName() string {
+	return "audit_chain_archive_heads"
+}
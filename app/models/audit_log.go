@@ -1,6 +1,9 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,18 +16,22 @@ const (
 	EntityTransaction                            
 	EntityBalance                                  
 	EntityRole                                     
+	EntityApiKey
+	EntityKycSubmission
 )
 
 func (e EntityType) IsValid() bool {
-	return e >= EntityUser && e <= EntityRole
+	return e >= EntityUser && e <= EntityKycSubmission
 }
 
 func (e EntityType) String() string {
 	names := map[EntityType]string{
-		EntityUser:        "user",
-		EntityTransaction: "transaction",
-		EntityBalance:     "balance",
-		EntityRole:        "role",
+		EntityUser:          "user",
+		EntityTransaction:   "transaction",
+		EntityBalance:       "balance",
+		EntityRole:          "role",
+		EntityApiKey:        "api_key",
+		EntityKycSubmission: "kyc_submission",
 	}
 	return names[e]
 }
@@ -43,10 +50,25 @@ const (
 	ActionWithdraw
 	ActionTransferIn
 	ActionTransferOut                      
+	ActionLockout
+	ActionUnlock
+	ActionClose
+	ActionRestore
+	ActionPurge
+	ActionFreeze
+	ActionSuspend
+	ActionReactivate
+	ActionExport
+	ActionErase
+	ActionKycSubmit
+	ActionKycApprove
+	ActionKycReject
+	ActionRoleChange
+	ActionRedact
 )
 
 func (a AuditAction) IsValid() bool {
-	return a >= ActionCreate && a <= ActionLogout
+	return a >= ActionCreate && a <= ActionRedact
 }
 
 func (a AuditAction) String() string {
@@ -62,19 +84,220 @@ func (a AuditAction) String() string {
 		ActionWithdraw:     "withdraw",    
 		ActionTransferIn:   "transfer_in", 
 		ActionTransferOut:  "transfer_out",
+		ActionLockout:      "lockout",
+		ActionUnlock:       "unlock",
+		ActionClose:        "close",
+		ActionRestore:      "restore",
+		ActionPurge:        "purge",
+		ActionFreeze:       "freeze",
+		ActionSuspend:      "suspend",
+		ActionReactivate:   "reactivate",
+		ActionExport:       "export",
+		ActionErase:        "erase",
+		ActionKycSubmit:    "kyc_submit",
+		ActionKycApprove:   "kyc_approve",
+		ActionKycReject:    "kyc_reject",
+		ActionRoleChange:   "role_change",
+		ActionRedact:       "redact",
 	}
 	return names[a]
 }
 
+// AuditContext describes who caused an entry and the request it came from.
+// Entries written by background jobs have no actor.
+type AuditContext struct {
+	ActorUserID *uuid.UUID `json:"actor_user_id,omitempty" gorm:"type:uuid"`
+	ActorRole   string     `json:"actor_role,omitempty" gorm:"type:varchar(50)"`
+	RequestID   string     `json:"request_id,omitempty" gorm:"type:varchar(100)"`
+	TraceID     string     `json:"trace_id,omitempty" gorm:"type:varchar(32)"`
+	IP          string     `json:"ip,omitempty" gorm:"column:ip;type:varchar(45)"`
+}
+
 type AuditLog struct {
-	ID         uuid.UUID   `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	EntityType EntityType  `json:"entity_type" gorm:"type:smallint;not null;index"`
-	EntityID   uuid.UUID   `json:"entity_id" gorm:"type:uuid;index"`
-	Action     AuditAction `json:"action" gorm:"type:smallint;not null"`
-	Details    string      `json:"details" gorm:"type:jsonb"`
-	CreatedAt  time.Time   `json:"created_at"`
+	ID          uuid.UUID   `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	EntityType  EntityType  `json:"entity_type" gorm:"type:smallint;not null;index"`
+	EntityID    uuid.UUID   `json:"entity_id" gorm:"type:uuid;index"`
+	Action      AuditAction `json:"action" gorm:"type:smallint;not null"`
+	Details     string      `json:"details" gorm:"type:jsonb"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Seq         int64       `json:"seq" gorm:"not null"`
+	PrevHash    string      `json:"prev_hash" gorm:"type:varchar(64)"`
+	ContentHash string      `json:"content_hash" gorm:"type:varchar(64)"`
+	Hash        string      `json:"hash" gorm:"type:varchar(64)"`
+	PiiHash     string      `json:"pii_hash,omitempty" gorm:"type:varchar(64)"`
+	RedactedAt  *time.Time  `json:"redacted_at"`
+	RedactedBy  *uuid.UUID  `json:"redacted_by,omitempty" gorm:"type:uuid"`
+	Changes     string      `json:"changes,omitempty" gorm:"type:jsonb;default:null"`
+	AuditContext
 }
 
 func (AuditLog) TableName() string {
 	return "audit_logs"
+}
+
+// AuditPiiDetailKeys are the details keys holding personal data. Together
+// with changes and the ip they are hashed apart from the rest of the entry,
+// so erasing them leaves the remaining content verifiable.
+var AuditPiiDetailKeys = []string{"email", "username", "pending_email", "ip", "user_agent"}
+
+// Seal links the entry to the previous entry of its chain, prev is nil for
+// the first entry.
+func (a *AuditLog) Seal(prev *AuditLog) {
+	a.Seq = 1
+	a.PrevHash = ""
+	if prev != nil {
+		a.Seq = prev.Seq + 1
+		a.PrevHash = prev.Hash
+	}
+
+	a.PiiHash = a.ComputePiiHash()
+	a.ContentHash = a.ComputeContentHash()
+	a.Hash = AuditChainHash(a.PrevHash, a.ContentHash)
+}
+
+// ComputeContentHash hashes the recorded content of the entry. Details are
+// re-encoded first so the key order and spacing of jsonb do not matter, and
+// empty fields added later are left out so older hashes stay valid.
+//
+// Entries sealed with a PII hash cover everything but the personal data
+// here, plus the PII hash itself, so this hash holds after an erasure.
+// Older entries without one cover all of their content.
+func (a *AuditLog) ComputeContentHash() string {
+	if a.PiiHash == "" {
+		return a.computeFullContentHash()
+	}
+
+	details, _ := splitAuditDetails(a.Details)
+	content, _ := json.Marshal(struct {
+		ID          uuid.UUID   `json:"id"`
+		EntityType  EntityType  `json:"entity_type"`
+		EntityID    uuid.UUID   `json:"entity_id"`
+		Action      AuditAction `json:"action"`
+		Details     interface{} `json:"details"`
+		CreatedAt   string      `json:"created_at"`
+		ActorUserID *uuid.UUID  `json:"actor_user_id,omitempty"`
+		ActorRole   string      `json:"actor_role,omitempty"`
+		RequestID   string      `json:"request_id,omitempty"`
+		TraceID     string      `json:"trace_id,omitempty"`
+		PiiHash     string      `json:"pii_hash"`
+	}{
+		ID:          a.ID,
+		EntityType:  a.EntityType,
+		EntityID:    a.EntityID,
+		Action:      a.Action,
+		Details:     details,
+		CreatedAt:   a.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		ActorUserID: a.ActorUserID,
+		ActorRole:   a.ActorRole,
+		RequestID:   a.RequestID,
+		TraceID:     a.TraceID,
+		PiiHash:     a.PiiHash,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputePiiHash hashes the personal data of the entry: the details keys in
+// AuditPiiDetailKeys, changes and the ip.
+func (a *AuditLog) ComputePiiHash() string {
+	_, details := splitAuditDetails(a.Details)
+	content, _ := json.Marshal(struct {
+		Details interface{} `json:"details,omitempty"`
+		Changes interface{} `json:"changes,omitempty"`
+		IP      string      `json:"ip,omitempty"`
+	}{
+		Details: details,
+		Changes: canonicalJSON(a.Changes),
+		IP:      a.IP,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *AuditLog) computeFullContentHash() string {
+	content, _ := json.Marshal(struct {
+		ID         uuid.UUID   `json:"id"`
+		EntityType EntityType  `json:"entity_type"`
+		EntityID   uuid.UUID   `json:"entity_id"`
+		Action     AuditAction `json:"action"`
+		Details    interface{} `json:"details"`
+		CreatedAt  string      `json:"created_at"`
+		Changes    interface{} `json:"changes,omitempty"`
+		AuditContext
+	}{
+		ID:           a.ID,
+		EntityType:   a.EntityType,
+		EntityID:     a.EntityID,
+		Action:       a.Action,
+		Details:      canonicalJSON(a.Details),
+		CreatedAt:    a.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		Changes:      canonicalJSON(a.Changes),
+		AuditContext: a.AuditContext,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitAuditDetails separates the personal data keys from the rest of the
+// details. Details that are not an object stay whole on the rest side.
+func splitAuditDetails(value string) (rest interface{}, pii map[string]interface{}) {
+	decoded := canonicalJSON(value)
+	object, ok := decoded.(map[string]interface{})
+	if !ok {
+		return decoded, nil
+	}
+
+	for _, key := range AuditPiiDetailKeys {
+		if v, ok := object[key]; ok {
+			if pii == nil {
+				pii = make(map[string]interface{})
+			}
+			pii[key] = v
+			delete(object, key)
+		}
+	}
+
+	return object, pii
+}
+
+func (a *AuditLog) IsRedacted() bool {
+	return a.RedactedAt != nil
+}
+
+// AuditRedaction is the details of an ActionRedact entry: the entries whose
+// personal data was erased. The verifier accepts a changed PII hash only on
+// entries listed here.
+type AuditRedaction struct {
+	Entries []uuid.UUID `json:"entries"`
+}
+
+func canonicalJSON(value string) interface{} {
+	var decoded interface{}
+	if value != "" {
+		json.Unmarshal([]byte(value), &decoded)
+	}
+	return decoded
+}
+
+func AuditChainHash(prevHash, contentHash string) string {
+	sum := sha256.Sum256([]byte(prevHash + contentHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainAnchor records the head of an audit chain at a point in time, so
+// entries removed from the end of a chain are still detected.
+type AuditChainAnchor struct {
+	ID         uint64     `json:"id" gorm:"primaryKey"`
+	EntityType EntityType `json:"entity_type" gorm:"type:smallint;not null"`
+	EntityID   uuid.UUID  `json:"entity_id" gorm:"type:uuid;not null"`
+	Seq        int64      `json:"seq" gorm:"not null"`
+	Hash       string     `json:"hash" gorm:"type:varchar(64);not null"`
+	AnchoredAt time.Time  `json:"anchored_at"`
+}
+
+func (AuditChainAnchor) TableName() string {
+	return "audit_chain_anchors"
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditOutboxEvent is an audit event waiting to be chained into the audit
+// log. It shares the id of the entry it becomes.
+type AuditOutboxEvent struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
+	Event     string    `json:"event" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (AuditOutboxEvent) TableName() string {
+	return "audit_outbox"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus uint
+
+const (
+	DataExportPending DataExportStatus = iota + 1
+	DataExportCompleted
+	DataExportFailed
+)
+
+func (s DataExportStatus) IsValid() bool {
+	return s >= DataExportPending && s <= DataExportFailed
+}
+
+func (s DataExportStatus) String() string {
+	names := map[DataExportStatus]string{
+		DataExportPending:   "pending",
+		DataExportCompleted: "completed",
+		DataExportFailed:    "failed",
+	}
+	return names[s]
+}
+
+type DataExport struct {
+	ID          uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	Status      DataExportStatus `json:"status" gorm:"type:smallint;not null;default:1"`
+	BlobKey     *string          `json:"-" gorm:"type:varchar(255)"`
+	Size        int64            `json:"size"`
+	Error       *string          `json:"-" gorm:"type:varchar(255)"`
+	CompletedAt *time.Time       `json:"completed_at"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+func (e *DataExport) IsDownloadable() bool {
+	return e.Status == DataExportCompleted && e.BlobKey != nil && time.Now().Before(e.ExpiresAt)
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type KycTier uint
+
+const (
+	KycTierBasic KycTier = iota + 1
+	KycTierVerified
+	KycTierEnhanced
+)
+
+func (t KycTier) IsValid() bool {
+	return t >= KycTierBasic && t <= KycTierEnhanced
+}
+
+func (t KycTier) String() string {
+	names := map[KycTier]string{
+		KycTierBasic:    "basic",
+		KycTierVerified: "verified",
+		KycTierEnhanced: "enhanced",
+	}
+	return names[t]
+}
+
+// KycPolicy describes what a tier may do. MaxAmount caps a single
+// transaction, DailyOutgoingLimit caps withdrawals and outgoing transfers
+// per calendar day. Zero means unlimited.
+type KycPolicy struct {
+	AllowedTypes       []TransactionType
+	MaxAmount          float64
+	DailyOutgoingLimit float64
+}
+
+var KycPolicies = map[KycTier]KycPolicy{
+	KycTierBasic: {
+		AllowedTypes: []TransactionType{TxTypeDeposit},
+		MaxAmount:    1000,
+	},
+	KycTierVerified: {
+		AllowedTypes:       []TransactionType{TxTypeDeposit, TxTypeWithdraw, TxTypeTransfer},
+		MaxAmount:          10000,
+		DailyOutgoingLimit: 20000,
+	},
+	KycTierEnhanced: {
+		AllowedTypes:       []TransactionType{TxTypeDeposit, TxTypeWithdraw, TxTypeTransfer},
+		MaxAmount:          100000,
+		DailyOutgoingLimit: 500000,
+	},
+}
+
+func (t KycTier) Policy() KycPolicy {
+	if policy, ok := KycPolicies[t]; ok {
+		return policy
+	}
+	return KycPolicies[KycTierBasic]
+}
+
+func (p KycPolicy) Allows(txType TransactionType) bool {
+	for _, allowed := range p.AllowedTypes {
+		if allowed == txType {
+			return true
+		}
+	}
+	return false
+}
+
+type KycStatus uint
+
+const (
+	KycStatusPending KycStatus = iota + 1
+	KycStatusApproved
+	KycStatusRejected
+)
+
+func (s KycStatus) IsValid() bool {
+	return s >= KycStatusPending && s <= KycStatusRejected
+}
+
+func (s KycStatus) String() string {
+	names := map[KycStatus]string{
+		KycStatusPending:  "pending",
+		KycStatusApproved: "approved",
+		KycStatusRejected: "rejected",
+	}
+	return names[s]
+}
+
+type KycSubmission struct {
+	ID                  uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID              uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	RequestedTier       KycTier    `json:"requested_tier" gorm:"type:smallint;not null"`
+	Status              KycStatus  `json:"status" gorm:"type:smallint;not null;default:1"`
+	FullName            string     `json:"full_name" gorm:"type:varchar(150);not null"`
+	DateOfBirth         *time.Time `json:"date_of_birth" gorm:"type:date"`
+	Country             string     `json:"country" gorm:"type:char(2);not null"`
+	DocumentType        string     `json:"document_type" gorm:"type:varchar(30);not null"`
+	DocumentNumber      string     `json:"document_number" gorm:"type:varchar(50);not null"`
+	DocumentBlobKey     string     `json:"-" gorm:"type:varchar(255);not null"`
+	DocumentContentType string     `json:"-" gorm:"type:varchar(100);not null"`
+	ReviewerID          *uuid.UUID `json:"reviewer_id" gorm:"type:uuid"`
+	ReviewNote          *string    `json:"review_note" gorm:"type:varchar(500)"`
+	ReviewedAt          *time.Time `json:"reviewed_at"`
+	ErasedAt            *time.Time `json:"erased_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (KycSubmission) TableName() string {
+	return "kyc_submissions"
+}
+
+func (k *KycSubmission) IsPending() bool {
+	return k.Status == KycStatusPending
+}
+
+func (k *KycSubmission) IsErased() bool {
+	return k.ErasedAt != nil
+}
@@ -0,0 +1,25 @@
+package models
+
+const (
+	PermUsersRead          = "users:read"
+	PermUsersUpdate        = "users:update"
+	PermUsersDelete        = "users:delete"
+	PermUsersUnlock        = "users:unlock"
+	PermUsersFreeze        = "users:freeze"
+	PermUsersErase         = "users:erase"
+	PermKycReview          = "kyc:review"
+	PermAuditRead          = "audit:read"
+	PermRolesManage        = "roles:manage"
+	PermTransactionsStats  = "transactions:stats"
+	PermTransactionsReplay = "transactions:replay"
+)
+
+type Permission struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Description string `json:"description" gorm:"type:varchar(255)"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type Role uint
 
 const (
@@ -12,13 +19,28 @@ func (r Role) IsValid() bool {
 	return r >= RoleUser && r <= RoleMod
 }
 
+func (r Role) IsSystem() bool {
+	return r.IsValid()
+}
+
 func (r Role) String() string {
 	names := map[Role]string{
 		RoleUser:  "user",
 		RoleAdmin: "admin",
 		RoleMod:   "mod",
 	}
-	return names[r]
+	if name, ok := names[r]; ok {
+		return name
+	}
+	return "role_" + strconv.Itoa(int(r))
+}
+
+// EntityID maps the small integer role id onto the uuid audit entity id.
+func (r Role) EntityID() uuid.UUID {
+	var id uuid.UUID
+	id[14] = byte(r >> 8)
+	id[15] = byte(r)
+	return id
 }
 
 func ToRole(s string) Role {
@@ -31,4 +53,25 @@ func ToRole(s string) Role {
 		return RoleMod
 	}
 	return RoleUser
-}
\ No newline at end of file
+}
+
+type RoleDefinition struct {
+	ID          Role         `json:"id" gorm:"primaryKey;type:smallint"`
+	Name        string       `json:"name" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Description string       `json:"description" gorm:"type:varchar(255)"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;joinForeignKey:RoleID;joinReferences:PermissionID"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func (RoleDefinition) TableName() string {
+	return "roles"
+}
+
+func (r *RoleDefinition) PermissionNames() []string {
+	names := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -63,6 +63,9 @@ type Transaction struct {
 	Amount     float64           `json:"amount" gorm:"type:decimal(15,2);not null"`
 	Type       TransactionType   `json:"type" gorm:"type:smallint;not null"`
 	Status     TransactionStatus `json:"status" gorm:"type:smallint;default:1"`
+	// CallbackURL receives the final state of an asynchronous transaction
+	CallbackURL   *string `json:"-" gorm:"type:text"`
+	FailureReason *string `json:"failure_reason" gorm:"type:text"`
 	CreatedAt  time.Time         `json:"created_at"`
 
 	FromUser *User `json:"from_user" gorm:"foreignKey:FromUserID"`
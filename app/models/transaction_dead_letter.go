@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeadLetterStatus uint
+
+const (
+	DeadLetterPending DeadLetterStatus = iota + 1
+	DeadLetterReplayed
+	DeadLetterDiscarded
+)
+
+func (s DeadLetterStatus) IsValid() bool {
+	return s >= DeadLetterPending && s <= DeadLetterDiscarded
+}
+
+func (s DeadLetterStatus) String() string {
+	names := map[DeadLetterStatus]string{
+		DeadLetterPending:   "pending",
+		DeadLetterReplayed:  "replayed",
+		DeadLetterDiscarded: "discarded",
+	}
+	return names[s]
+}
+
+// TransactionDeadLetter keeps an asynchronous transaction job that failed for
+// reasons other than the transaction itself, until an admin replays or
+// discards it. A replay runs the job again under its original id.
+type TransactionDeadLetter struct {
+	ID                    uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	JobID                 uuid.UUID        `json:"job_id" gorm:"type:uuid;not null;index"`
+	Type                  TransactionType  `json:"type" gorm:"type:smallint;not null"`
+	FromUserID            *uuid.UUID       `json:"from_user_id" gorm:"type:uuid"`
+	ToUserID              *uuid.UUID       `json:"to_user_id" gorm:"type:uuid"`
+	Amount                float64          `json:"amount" gorm:"type:decimal(15,2);not null"`
+	AuditContext          AuditContext     `json:"audit_context" gorm:"embedded;embeddedPrefix:audit_"`
+	Async                 bool             `json:"async" gorm:"not null;default:false"`
+	Error                 string           `json:"error" gorm:"type:text;not null"`
+	Attempts              int              `json:"attempts" gorm:"not null"`
+	Status                DeadLetterStatus `json:"status" gorm:"type:smallint;not null;default:1"`
+	ReplayedTransactionID *uuid.UUID       `json:"replayed_transaction_id" gorm:"type:uuid"`
+	ResolvedBy            *uuid.UUID       `json:"resolved_by" gorm:"type:uuid"`
+	ResolvedAt            *time.Time       `json:"resolved_at"`
+	SubmittedAt           time.Time        `json:"submitted_at"`
+	CreatedAt             time.Time        `json:"created_at"`
+}
+
+func (TransactionDeadLetter) TableName() string {
+	return "transaction_dead_letters"
+}
+
+func (d *TransactionDeadLetter) IsPending() bool {
+	return d.Status == DeadLetterPending
+}
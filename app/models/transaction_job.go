@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransactionJobStatus uint
+
+const (
+	TxJobQueued TransactionJobStatus = iota + 1
+	TxJobProcessing
+)
+
+func (s TransactionJobStatus) IsValid() bool {
+	return s >= TxJobQueued && s <= TxJobProcessing
+}
+
+func (s TransactionJobStatus) String() string {
+	names := map[TransactionJobStatus]string{
+		TxJobQueued:     "queued",
+		TxJobProcessing: "processing",
+	}
+	return names[s]
+}
+
+// TransactionJobRecord is an accepted transaction waiting in the durable
+// queue. It shares the id of its pending transaction and is deleted once the
+// transaction was processed.
+type TransactionJobRecord struct {
+	ID           uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid"`
+	Type         TransactionType      `json:"type" gorm:"type:smallint;not null"`
+	FromUserID   *uuid.UUID           `json:"from_user_id" gorm:"type:uuid"`
+	ToUserID     *uuid.UUID           `json:"to_user_id" gorm:"type:uuid"`
+	Amount       float64              `json:"amount" gorm:"type:decimal(15,2);not null"`
+	AuditContext AuditContext         `json:"audit_context" gorm:"embedded;embeddedPrefix:audit_"`
+	Status       TransactionJobStatus `json:"status" gorm:"type:smallint;not null;default:1"`
+	Attempts     int                  `json:"attempts" gorm:"not null;default:0"`
+	LockedBy     *string              `json:"locked_by" gorm:"type:varchar(255)"`
+	LockedUntil  *time.Time           `json:"locked_until"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+func (TransactionJobRecord) TableName() string {
+	return "transaction_jobs"
+}
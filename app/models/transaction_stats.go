@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// TransactionStatsSlots spreads the counters of one hour, type and status
+// over several rows, so concurrent transactions rarely wait on the same row.
+const TransactionStatsSlots = 8
+
+// TransactionStatsHourly counts final transactions per hour of creation,
+// type and status. Aggregates read it with the slots summed up.
+type TransactionStatsHourly struct {
+	Bucket    time.Time         `json:"bucket" gorm:"primaryKey"`
+	Type      TransactionType   `json:"type" gorm:"primaryKey;type:smallint"`
+	Status    TransactionStatus `json:"status" gorm:"primaryKey;type:smallint"`
+	Slot      int               `json:"-" gorm:"primaryKey;type:smallint"`
+	Count     int64             `json:"count" gorm:"not null;default:0"`
+	Amount    float64           `json:"amount" gorm:"type:decimal(20,2);not null;default:0"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func (TransactionStatsHourly) TableName() string {
+	return "transaction_stats_hourly"
+}
@@ -4,18 +4,95 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+type UserStatus uint
+
+const (
+	UserStatusActive UserStatus = iota + 1
+	UserStatusClosed
+	UserStatusFrozen
+	UserStatusSuspended
+)
+
+func (s UserStatus) IsValid() bool {
+	return s >= UserStatusActive && s <= UserStatusSuspended
+}
+
+func (s UserStatus) String() string {
+	names := map[UserStatus]string{
+		UserStatusActive:    "active",
+		UserStatusClosed:    "closed",
+		UserStatusFrozen:    "frozen",
+		UserStatusSuspended: "suspended",
+	}
+	return names[s]
+}
+
 type User struct {
-	ID           uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Username     string    `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`
-	Email        string    `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
-	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"`
-	RoleID       Role      `json:"role_id" gorm:"type:smallint;not null"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                         uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Username                   string         `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Email                      string         `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
+	PasswordHash               string         `json:"-" gorm:"type:varchar(255);not null"`
+	RoleID                     Role           `json:"role_id" gorm:"type:smallint;not null"`
+	Status                     UserStatus     `json:"status" gorm:"type:smallint;not null;default:1"`
+	PendingEmail               *string        `json:"pending_email" gorm:"type:varchar(100)"`
+	EmailVerificationHash      *string        `json:"-" gorm:"type:varchar(64)"`
+	EmailVerificationExpiresAt *time.Time     `json:"-"`
+	KycTier                    KycTier        `json:"kyc_tier" gorm:"type:smallint;not null;default:1"`
+	StatusReason               *string        `json:"status_reason" gorm:"type:varchar(255)"`
+	StatusExpiresAt            *time.Time     `json:"status_expires_at"`
+	StatusChangedBy            *uuid.UUID     `json:"status_changed_by" gorm:"type:uuid"`
+	ClosedAt                   *time.Time     `json:"closed_at"`
+	ErasedAt                   *time.Time     `json:"erased_at"`
+	CreatedAt                  time.Time      `json:"created_at"`
+	UpdatedAt                  time.Time      `json:"updated_at"`
+	DeletedAt                  gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (User) TableName() string {
 	return "users"
-}
\ No newline at end of file
+}
+
+func (u *User) IsErased() bool {
+	return u.ErasedAt != nil
+}
+
+func (u *User) IsClosed() bool {
+	return u.Status == UserStatusClosed
+}
+
+// EffectiveStatus treats a freeze or suspension whose expiry has passed as
+// active again, so expired restrictions lapse without a background job.
+func (u *User) EffectiveStatus() UserStatus {
+	if u.Status != UserStatusFrozen && u.Status != UserStatusSuspended {
+		return u.Status
+	}
+
+	if u.StatusExpiresAt != nil && time.Now().After(*u.StatusExpiresAt) {
+		return UserStatusActive
+	}
+
+	return u.Status
+}
+
+func (u *User) IsSuspended() bool {
+	return u.EffectiveStatus() == UserStatusSuspended
+}
+
+func (u *User) CanSend() bool {
+	return u.EffectiveStatus() == UserStatusActive
+}
+
+// CanReceive reports whether incoming money may be credited. Frozen accounts
+// keep receiving unless the freeze policy blocks all movement.
+func (u *User) CanReceive(freezeBlocksAll bool) bool {
+	switch u.EffectiveStatus() {
+	case UserStatusActive:
+		return true
+	case UserStatusFrozen:
+		return !freezeBlocksAll
+	}
+	return false
+}
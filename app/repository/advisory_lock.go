@@ -0,0 +1,39 @@
+package repository
+
+import "context"
+
+// TryAdvisoryLock takes the session level advisory lock named key without
+// waiting. The lock lives on a connection taken out of the pool for it, so
+// it holds across the transactions of the caller. It returns false when
+// another session holds the lock, otherwise a function releasing the lock
+// and the connection. Should the connection die the lock is released too.
+func TryAdvisoryLock(key string) (func(), bool, error) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", key)
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IApiKeyRepository interface {
+	Create(apiKey *models.ApiKey) error
+	FindByPrefix(prefix string) (*models.ApiKey, error)
+	FindByUserID(userID uuid.UUID) ([]models.ApiKey, error)
+	FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.ApiKey, error)
+	Revoke(id uuid.UUID) error
+	RevokeAllByUserID(userID uuid.UUID) error
+	TouchLastUsed(id uuid.UUID, usedAt time.Time) error
+}
+
+type ApiKeyRepository struct{}
+
+func NewApiKeyRepository() *ApiKeyRepository {
+	return &ApiKeyRepository{}
+}
+
+func (r *ApiKeyRepository) Create(apiKey *models.ApiKey) error {
+	return DB.Create(apiKey).Error
+}
+
+func (r *ApiKeyRepository) FindByPrefix(prefix string) (*models.ApiKey, error) {
+	var apiKey models.ApiKey
+	if err := DB.Preload("User").Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *ApiKeyRepository) FindByUserID(userID uuid.UUID) ([]models.ApiKey, error) {
+	var apiKeys []models.ApiKey
+	err := DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error
+
+	return apiKeys, err
+}
+
+func (r *ApiKeyRepository) FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.ApiKey, error) {
+	var apiKey models.ApiKey
+	if err := DB.Where("id = ? AND user_id = ?", id, userID).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *ApiKeyRepository) Revoke(id uuid.UUID) error {
+	return DB.Model(&models.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *ApiKeyRepository) RevokeAllByUserID(userID uuid.UUID) error {
+	return DB.Model(&models.ApiKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *ApiKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	return DB.Model(&models.ApiKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
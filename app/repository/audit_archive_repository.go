@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IAuditArchiveRepository interface {
+	GetDB() *gorm.DB
+	ListPartitions() ([]AuditPartition, error)
+	CreatePartition(month time.Time) error
+	LockPartition(tx *gorm.DB, partition AuditPartition) error
+	ListDetachedPartitions() ([]AuditPartition, error)
+	DetachPartition(partition AuditPartition) error
+	DropPartition(tx *gorm.DB, partition AuditPartition) error
+	CountExpired(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (int64, error)
+	FindExpiredRows(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (*sql.Rows, error)
+	DeleteExpired(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (int64, error)
+	SaveArchiveHeads(tx *gorm.DB, partition AuditPartition) error
+	CreateArchive(tx *gorm.DB, archive *models.AuditLogArchive) error
+	FindArchiveHeads(entityType models.EntityType, entityID *uuid.UUID) ([]models.AuditChainArchiveHead, error)
+}
+
+// AuditPartition is one monthly partition of audit_logs, covering
+// [From, To) in UTC.
+type AuditPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+var auditPartitionName = regexp.MustCompile(`^audit_logs_(\d{4})_(\d{2})$`)
+
+func NewAuditPartition(month time.Time) AuditPartition {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return AuditPartition{
+		Name: fmt.Sprintf("audit_logs_%04d_%02d", from.Year(), from.Month()),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+type AuditArchiveRepository struct{}
+
+func NewAuditArchiveRepository() *AuditArchiveRepository {
+	return &AuditArchiveRepository{}
+}
+
+func (r *AuditArchiveRepository) GetDB() *gorm.DB {
+	return DB
+}
+
+// ListPartitions returns the attached monthly partitions, oldest first. The
+// default partition is left out.
+func (r *AuditArchiveRepository) ListPartitions() ([]AuditPartition, error) {
+	var names []string
+	err := DB.Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'audit_logs'`).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAuditPartitions(names), nil
+}
+
+// ListDetachedPartitions returns partitions that were detached but not
+// dropped yet, after archiving was interrupted.
+func (r *AuditArchiveRepository) ListDetachedPartitions() ([]AuditPartition, error) {
+	var names []string
+	err := DB.Raw(`SELECT relname FROM pg_class
+		WHERE relkind = 'r' AND NOT relispartition AND relname ~ '^audit_logs_[0-9]{4}_[0-9]{2}$'`).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAuditPartitions(names), nil
+}
+
+func parseAuditPartitions(names []string) []AuditPartition {
+	partitions := make([]AuditPartition, 0, len(names))
+	for _, name := range names {
+		match := auditPartitionName.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		month, err := time.Parse("2006-01", match[1]+"-"+match[2])
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, NewAuditPartition(month))
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].From.Before(partitions[j].From)
+	})
+
+	return partitions
+}
+
+func (r *AuditArchiveRepository) CreatePartition(month time.Time) error {
+	partition := NewAuditPartition(month)
+	return DB.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF audit_logs FOR VALUES FROM ('%s') TO ('%s')`,
+		partition.Name,
+		partition.From.Format(time.RFC3339),
+		partition.To.Format(time.RFC3339),
+	)).Error
+}
+
+// LockPartition blocks writes to the partition until the transaction ends,
+// so the exported rows are exactly the deleted ones.
+func (r *AuditArchiveRepository) LockPartition(tx *gorm.DB, partition AuditPartition) error {
+	return tx.Exec("LOCK TABLE " + partition.Name + " IN SHARE MODE").Error
+}
+
+// DetachPartition detaches the partition outside of any transaction, so
+// the parent table is only locked for the detach itself. Postgres refuses
+// DETACH CONCURRENTLY while a default partition exists; then a plain detach
+// runs on its own and gives up instead of queueing every writer behind a
+// long reader. A concurrent detach that was interrupted is finalized.
+func (r *AuditArchiveRepository) DetachPartition(partition AuditPartition) error {
+	var state struct {
+		Pending    bool
+		HasDefault bool
+	}
+	err := DB.Raw(`SELECT i.inhdetachpending AS pending, t.partdefid <> 0 AS has_default
+		FROM pg_inherits i
+		JOIN pg_partitioned_table t ON t.partrelid = i.inhparent
+		WHERE i.inhparent = 'audit_logs'::regclass AND i.inhrelid = ?::regclass`, partition.Name).Scan(&state).Error
+	if err != nil {
+		return err
+	}
+
+	if state.Pending {
+		return DB.Exec("ALTER TABLE audit_logs DETACH PARTITION " + partition.Name + " FINALIZE").Error
+	}
+
+	if !state.HasDefault {
+		return DB.Exec("ALTER TABLE audit_logs DETACH PARTITION " + partition.Name + " CONCURRENTLY").Error
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL lock_timeout = '5s'").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE audit_logs DETACH PARTITION " + partition.Name).Error
+	})
+}
+
+func (r *AuditArchiveRepository) DropPartition(tx *gorm.DB, partition AuditPartition) error {
+	return tx.Exec("DROP TABLE " + partition.Name).Error
+}
+
+// expiredQuery selects the entries of a type created before the cutoff, or
+// every entry of the partition when entityType is 0.
+func (r *AuditArchiveRepository) expiredQuery(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) *gorm.DB {
+	query := tx.Table(partition.Name)
+	if entityType != 0 {
+		query = query.Where("entity_type = ? AND created_at < ?", entityType, before)
+	}
+	return query
+}
+
+func (r *AuditArchiveRepository) CountExpired(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (int64, error) {
+	var count int64
+	err := r.expiredQuery(tx, partition, entityType, before).Count(&count).Error
+	return count, err
+}
+
+func (r *AuditArchiveRepository) FindExpiredRows(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (*sql.Rows, error) {
+	return r.expiredQuery(tx, partition, entityType, before).Order("created_at, id").Rows()
+}
+
+// DeleteExpired removes archived entries of a type and moves the archive
+// head of every affected chain forward.
+func (r *AuditArchiveRepository) DeleteExpired(tx *gorm.DB, partition AuditPartition, entityType models.EntityType, before time.Time) (int64, error) {
+	var deleted int64
+	err := tx.Raw(`WITH deleted AS (
+			DELETE FROM `+partition.Name+` WHERE entity_type = ? AND created_at < ?
+			RETURNING entity_type, entity_id, seq, hash
+		), heads AS (
+			INSERT INTO audit_chain_archive_heads (entity_type, entity_id, seq, hash, archived_at)
+			SELECT DISTINCT ON (entity_type, entity_id) entity_type, entity_id, seq, hash, now()
+			FROM deleted WHERE hash IS NOT NULL
+			ORDER BY entity_type, entity_id, seq DESC
+			ON CONFLICT (entity_type, entity_id) DO UPDATE
+			SET seq = EXCLUDED.seq, hash = EXCLUDED.hash, archived_at = EXCLUDED.archived_at
+			WHERE audit_chain_archive_heads.seq < EXCLUDED.seq
+		)
+		SELECT count(*) FROM deleted`, entityType, before).Scan(&deleted).Error
+
+	return deleted, err
+}
+
+// SaveArchiveHeads moves the archive head of every chain in the partition
+// forward, before the partition is dropped.
+func (r *AuditArchiveRepository) SaveArchiveHeads(tx *gorm.DB, partition AuditPartition) error {
+	return tx.Exec(`INSERT INTO audit_chain_archive_heads (entity_type, entity_id, seq, hash, archived_at)
+		SELECT DISTINCT ON (entity_type, entity_id) entity_type, entity_id, seq, hash, now()
+		FROM ` + partition.Name + ` WHERE hash IS NOT NULL
+		ORDER BY entity_type, entity_id, seq DESC
+		ON CONFLICT (entity_type, entity_id) DO UPDATE
+		SET seq = EXCLUDED.seq, hash = EXCLUDED.hash, archived_at = EXCLUDED.archived_at
+		WHERE audit_chain_archive_heads.seq < EXCLUDED.seq`).Error
+}
+
+func (r *AuditArchiveRepository) CreateArchive(tx *gorm.DB, archive *models.AuditLogArchive) error {
+	return tx.Create(archive).Error
+}
+
+func (r *AuditArchiveRepository) FindArchiveHeads(entityType models.EntityType, entityID *uuid.UUID) ([]models.AuditChainArchiveHead, error) {
+	query := DB.Model(&models.AuditChainArchiveHead{})
+
+	if entityType != 0 {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	if entityID != nil {
+		query = query.Where("entity_id = ?", *entityID)
+	}
+
+	var heads []models.AuditChainArchiveHead
+	err := query.Find(&heads).Error
+
+	return heads, err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAuditOutboxRepository interface {
+	Insert(tx *gorm.DB, auditLog *models.AuditLog) error
+	Relay(limit int) (int, error)
+	RelayAll(tx *gorm.DB) error
+	Count() (int64, error)
+}
+
+type AuditOutboxRepository struct {
+	auditRepo *AuditRepository
+}
+
+func NewAuditOutboxRepository() *AuditOutboxRepository {
+	return &AuditOutboxRepository{auditRepo: NewAuditRepository()}
+}
+
+// Insert stores the event within tx, it becomes part of the audit log only
+// if tx commits.
+func (r *AuditOutboxRepository) Insert(tx *gorm.DB, auditLog *models.AuditLog) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	event, err := json.Marshal(auditLog)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.AuditOutboxEvent{
+		ID:        auditLog.ID,
+		Event:     string(event),
+		CreatedAt: auditLog.CreatedAt,
+	}).Error
+}
+
+// Relay moves up to limit of the oldest events into the audit log and
+// returns how many it moved. Events other instances are relaying at the
+// same time are skipped.
+func (r *AuditOutboxRepository) Relay(limit int) (int, error) {
+	relayed := 0
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		relayed, err = r.relay(tx, clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}, limit)
+		return err
+	})
+
+	return relayed, err
+}
+
+// RelayAll moves every waiting event into the audit log within tx, waiting
+// for events other instances are relaying, so that a following statement in
+// tx sees all of them in audit_logs.
+func (r *AuditOutboxRepository) RelayAll(tx *gorm.DB) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	_, err := r.relay(tx, clause.Locking{Strength: "UPDATE"}, 0)
+	return err
+}
+
+func (r *AuditOutboxRepository) relay(tx *gorm.DB, locking clause.Locking, limit int) (int, error) {
+	query := tx.Clauses(locking).Order("created_at")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var events []models.AuditOutboxEvent
+	if err := query.Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	auditLogs := make([]models.AuditLog, 0, len(events))
+	ids := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+
+		var auditLog models.AuditLog
+		if err := json.Unmarshal([]byte(event.Event), &auditLog); err != nil {
+			return 0, err
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	if err := r.auditRepo.createBatch(tx, auditLogs); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Delete(&models.AuditOutboxEvent{}, "id IN ?", ids).Error; err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+func (r *AuditOutboxRepository) Count() (int64, error) {
+	var count int64
+	err := DB.Model(&models.AuditOutboxEvent{}).Count(&count).Error
+	return count, err
+}
@@ -1,9 +1,74 @@
 package repository
 
-import "backend-path/app/models"
+import (
+	"backend-path/app/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type IAuditLogRepository interface {
-	Create(auditLog *models.AuditLog) error
+	Create(tx *gorm.DB, auditLog *models.AuditLog) error
+	CreateBatch(auditLogs []models.AuditLog) error
+	FindByEntityID(entityID uuid.UUID) ([]models.AuditLog, error)
+	FindByIDs(ids []uuid.UUID) ([]models.AuditLog, error)
+	Find(filter AuditLogFilter, cursor *AuditLogCursor, limit int, ascending bool) ([]models.AuditLog, error)
+	FindChain(entityType models.EntityType, entityID *uuid.UUID, after *AuditChainPosition, limit int) ([]models.AuditLog, error)
+	SealLegacy() (int, error)
+	Anchor() ([]models.AuditChainAnchor, error)
+	FindLatestAnchors(entityType models.EntityType, entityID *uuid.UUID) ([]models.AuditChainAnchor, error)
+}
+
+type AuditLogFilter struct {
+	EntityType models.EntityType
+	EntityID   *uuid.UUID
+	Action     models.AuditAction
+	ActorID    *uuid.UUID
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Details    map[string]string
+}
+
+// AuditChainPosition is the last entry read while walking the chains in
+// (entity_type, entity_id, seq) order.
+type AuditChainPosition struct {
+	EntityType models.EntityType
+	EntityID   uuid.UUID
+	Seq        int64
+}
+
+// AuditLogCursor points at the last row of a page. Rows are ordered by
+// (created_at, id), so the cursor stays stable while new rows are written.
+type AuditLogCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func (c AuditLogCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseAuditLogCursor(value string) (*AuditLogCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var cursor AuditLogCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &cursor, nil
 }
 
 
@@ -13,6 +78,322 @@ func NewAuditRepository() *AuditRepository {
 	return &AuditRepository{}
 }
 
-func (r *AuditRepository) Create(auditLog *models.AuditLog) error {
-	return DB.Create(auditLog).Error
-}
\ No newline at end of file
+func (r *AuditRepository) Create(tx *gorm.DB, auditLog *models.AuditLog) error {
+	if tx == nil {
+		return DB.Transaction(func(tx *gorm.DB) error {
+			return r.create(tx, auditLog)
+		})
+	}
+
+	return r.create(tx, auditLog)
+}
+
+// CreateBatch inserts entries with preassigned ids. Entries that already
+// exist are skipped, so replaying a batch after a partial failure is safe.
+func (r *AuditRepository) CreateBatch(auditLogs []models.AuditLog) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return r.createBatch(tx, auditLogs)
+	})
+}
+
+// createBatch chains the entries within tx grouped by entity, which keeps
+// the lock order stable between concurrent batches.
+func (r *AuditRepository) createBatch(tx *gorm.DB, auditLogs []models.AuditLog) error {
+	sorted := make([]models.AuditLog, len(auditLogs))
+	copy(sorted, auditLogs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].EntityType != sorted[j].EntityType {
+			return sorted[i].EntityType < sorted[j].EntityType
+		}
+		return sorted[i].EntityID.String() < sorted[j].EntityID.String()
+	})
+
+	for i := range sorted {
+		var count int64
+		if err := tx.Model(&models.AuditLog{}).Where("id = ?", sorted[i].ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := r.create(tx, &sorted[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// create appends the entry to the hash chain of its entity. The advisory
+// lock serializes writers of one chain, so two entries never get the same
+// parent. It is the only guard: audit_logs is partitioned by created_at, so
+// the (entity_type, entity_id, seq) index cannot be unique.
+func (r *AuditRepository) create(tx *gorm.DB, auditLog *models.AuditLog) error {
+	if err := lockAuditChain(tx, auditLog.EntityType, auditLog.EntityID); err != nil {
+		return err
+	}
+
+	head, err := r.chainHead(tx, auditLog.EntityType, auditLog.EntityID)
+	if err != nil {
+		return err
+	}
+
+	if head != nil && head.Hash == "" {
+		if err := r.sealChain(tx, auditLog.EntityType, auditLog.EntityID); err != nil {
+			return err
+		}
+		if head, err = r.chainHead(tx, auditLog.EntityType, auditLog.EntityID); err != nil {
+			return err
+		}
+	}
+
+	if auditLog.ID == uuid.Nil {
+		auditLog.ID = uuid.New()
+	}
+	if auditLog.CreatedAt.IsZero() {
+		auditLog.CreatedAt = time.Now()
+	}
+	// postgres keeps microseconds, the hash has to cover the stored value
+	auditLog.CreatedAt = auditLog.CreatedAt.Truncate(time.Microsecond)
+
+	auditLog.Seal(head)
+	return tx.Create(auditLog).Error
+}
+
+func lockAuditChain(tx *gorm.DB, entityType models.EntityType, entityID uuid.UUID) error {
+	key := fmt.Sprintf("audit_chain:%d:%s", entityType, entityID)
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+func (r *AuditRepository) chainHead(tx *gorm.DB, entityType models.EntityType, entityID uuid.UUID) (*models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("seq DESC").
+		Limit(1).
+		Find(&logs).Error
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+
+	return &logs[0], nil
+}
+
+// sealChain computes the hashes of entries written before the chain
+// existed. The caller holds the chain lock.
+func (r *AuditRepository) sealChain(tx *gorm.DB, entityType models.EntityType, entityID uuid.UUID) error {
+	var logs []models.AuditLog
+	err := tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("seq").
+		Find(&logs).Error
+	if err != nil {
+		return err
+	}
+
+	var prev *models.AuditLog
+	for i := range logs {
+		if logs[i].Hash == "" {
+			logs[i].Seal(prev)
+			err := tx.Model(&models.AuditLog{}).Where("id = ?", logs[i].ID).Updates(map[string]interface{}{
+				"seq":          logs[i].Seq,
+				"prev_hash":    logs[i].PrevHash,
+				"pii_hash":     logs[i].PiiHash,
+				"content_hash": logs[i].ContentHash,
+				"hash":         logs[i].Hash,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		prev = &logs[i]
+	}
+
+	return nil
+}
+
+// SealLegacy chains every entry that has no hash yet and returns the number
+// of chains sealed.
+func (r *AuditRepository) SealLegacy() (int, error) {
+	var chains []AuditChainPosition
+	err := DB.Model(&models.AuditLog{}).
+		Distinct("entity_type", "entity_id").
+		Where("hash IS NULL").
+		Find(&chains).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chain := range chains {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := lockAuditChain(tx, chain.EntityType, chain.EntityID); err != nil {
+				return err
+			}
+			return r.sealChain(tx, chain.EntityType, chain.EntityID)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(chains), nil
+}
+
+// Anchor stores the head of every chain that grew since it was last
+// anchored and returns the new anchors.
+func (r *AuditRepository) Anchor() ([]models.AuditChainAnchor, error) {
+	var anchors []models.AuditChainAnchor
+	err := DB.Raw(`INSERT INTO audit_chain_anchors (entity_type, entity_id, seq, hash, anchored_at)
+		SELECT DISTINCT ON (l.entity_type, l.entity_id) l.entity_type, l.entity_id, l.seq, l.hash, now()
+		FROM audit_logs l
+		WHERE l.hash IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM audit_chain_anchors a
+			WHERE a.entity_type = l.entity_type AND a.entity_id = l.entity_id AND a.seq >= l.seq
+		)
+		ORDER BY l.entity_type, l.entity_id, l.seq DESC
+		RETURNING *`).Scan(&anchors).Error
+
+	return anchors, err
+}
+
+func (r *AuditRepository) FindLatestAnchors(entityType models.EntityType, entityID *uuid.UUID) ([]models.AuditChainAnchor, error) {
+	query := DB.Model(&models.AuditChainAnchor{})
+
+	if entityType != 0 {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	if entityID != nil {
+		query = query.Where("entity_id = ?", *entityID)
+	}
+
+	var anchors []models.AuditChainAnchor
+	err := query.Select("DISTINCT ON (entity_type, entity_id) *").
+		Order("entity_type, entity_id, seq DESC").
+		Find(&anchors).Error
+
+	return anchors, err
+}
+
+// FindChain returns entries in chain order, starting after the given
+// position.
+func (r *AuditRepository) FindChain(entityType models.EntityType, entityID *uuid.UUID, after *AuditChainPosition, limit int) ([]models.AuditLog, error) {
+	query := DB.Model(&models.AuditLog{})
+
+	if entityType != 0 {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	if entityID != nil {
+		query = query.Where("entity_id = ?", *entityID)
+	}
+
+	if after != nil {
+		query = query.Where("(entity_type, entity_id, seq) > (?, ?, ?)", after.EntityType, after.EntityID, after.Seq)
+	}
+
+	var logs []models.AuditLog
+	err := query.Order("entity_type, entity_id, seq").
+		Limit(limit).
+		Find(&logs).Error
+
+	return logs, err
+}
+
+func (r *AuditRepository) FindByEntityID(entityID uuid.UUID) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := DB.Where("entity_id = ?", entityID).Order("created_at").Find(&logs).Error
+
+	return logs, err
+}
+
+func (r *AuditRepository) FindByIDs(ids []uuid.UUID) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := DB.Where("id IN ?", ids).Find(&logs).Error
+
+	return logs, err
+}
+
+func (r *AuditRepository) Find(filter AuditLogFilter, cursor *AuditLogCursor, limit int, ascending bool) ([]models.AuditLog, error) {
+	query := DB.Model(&models.AuditLog{})
+
+	if filter.EntityType != 0 {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+
+	if filter.Action != 0 {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	if filter.ActorID != nil {
+		// entries written before the actor column existed keep it in details
+		query = query.Where("(actor_user_id = ? OR details->>'actor_id' = ?)", *filter.ActorID, filter.ActorID.String())
+	}
+
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	for key, value := range filter.Details {
+		// query values are text, a value that reads as a json number, bool
+		// or null also matches fields stored with that type
+		asString, _ := json.Marshal(map[string]string{key: value})
+		scalar, ok := detailsScalar(value)
+		if !ok {
+			query = query.Where("details @> ?::jsonb", string(asString))
+			continue
+		}
+
+		asScalar, _ := json.Marshal(map[string]interface{}{key: scalar})
+		query = query.Where("(details @> ?::jsonb OR details @> ?::jsonb)", string(asString), string(asScalar))
+	}
+
+	order := "DESC"
+	if ascending {
+		order = "ASC"
+	}
+
+	if cursor != nil {
+		comparison := "<"
+		if ascending {
+			comparison = ">"
+		}
+		query = query.Where("(created_at, id) "+comparison+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	var logs []models.AuditLog
+	err := query.Order("created_at " + order + ", id " + order).
+		Limit(limit).
+		Find(&logs).Error
+
+	return logs, err
+}
+
+// detailsScalar parses a details filter value as a json number, bool or
+// null.
+func detailsScalar(value string) (interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+
+	var scalar interface{}
+	if err := decoder.Decode(&scalar); err != nil || decoder.More() {
+		return nil, false
+	}
+
+	switch scalar.(type) {
+	case json.Number, bool, nil:
+		return scalar, true
+	}
+	return nil, false
+}
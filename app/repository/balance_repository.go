@@ -15,7 +15,7 @@ type IBalanceRepository interface {
 	Create(balance *models.Balance) error
 	Update(tx *gorm.DB, balance *models.Balance) error
 	Upsert(tx *gorm.DB, balance *models.Balance) error
-	GetBalanceHistory(userID uuid.UUID, limit, offset int) ([]models.AuditLog, int64, error)
+	GetBalanceHistory(userID uuid.UUID, since *time.Time, limit, offset int) ([]models.AuditLog, int64, error)
 	GetBalanceAtTime(userID uuid.UUID, timestamp time.Time) (*models.AuditLog, error)
 }
 
@@ -56,13 +56,20 @@ func (r *BalanceRepository) Upsert(tx *gorm.DB, balance *models.Balance) error {
 	}).Create(balance).Error
 }
 
-func (r *BalanceRepository) GetBalanceHistory(userID uuid.UUID, limit, offset int) ([]models.AuditLog, int64, error) {
+// GetBalanceHistory reads entries created since the given time, or all of
+// them when since is nil. A start lets postgres skip the audit_logs
+// partitions of older months.
+func (r *BalanceRepository) GetBalanceHistory(userID uuid.UUID, since *time.Time, limit, offset int) ([]models.AuditLog, int64, error) {
 	var logs []models.AuditLog
 	var total int64
 
 	query := DB.Model(&models.AuditLog{}).
 		Where("entity_type = ? AND entity_id = ?", models.EntityBalance, userID)
 
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+
 	query.Count(&total)
 
 	err := query.Order("created_at DESC").
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IDataExportRepository interface {
+	Create(export *models.DataExport) error
+	FindByID(id uuid.UUID) (*models.DataExport, error)
+	FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.DataExport, error)
+	FindPendingByUserID(userID uuid.UUID) (*models.DataExport, error)
+	FindByUserID(userID uuid.UUID) ([]models.DataExport, error)
+	FindExpired(before time.Time, limit int) ([]models.DataExport, error)
+	Update(export *models.DataExport) error
+	Delete(id uuid.UUID) error
+}
+
+type DataExportRepository struct{}
+
+func NewDataExportRepository() *DataExportRepository {
+	return &DataExportRepository{}
+}
+
+func (r *DataExportRepository) Create(export *models.DataExport) error {
+	return DB.Create(export).Error
+}
+
+func (r *DataExportRepository) FindByID(id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := DB.Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := DB.Where("id = ? AND user_id = ?", id, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) FindPendingByUserID(userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	err := DB.Where("user_id = ? AND status = ?", userID, models.DataExportPending).
+		Order("created_at DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) FindByUserID(userID uuid.UUID) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error
+
+	return exports, err
+}
+
+func (r *DataExportRepository) FindExpired(before time.Time, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := DB.Where("expires_at < ?", before).Order("expires_at").Limit(limit).Find(&exports).Error
+
+	return exports, err
+}
+
+func (r *DataExportRepository) Update(export *models.DataExport) error {
+	return DB.Save(export).Error
+}
+
+func (r *DataExportRepository) Delete(id uuid.UUID) error {
+	return DB.Delete(&models.DataExport{}, "id = ?", id).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrJobNotOwned is returned for a transaction job this instance no longer
+// holds, its lease ran out and another instance may have claimed it.
+var ErrJobNotOwned = errors.New("transaction job is no longer held by this instance")
+
+// RetryableCause names the postgres failure behind err when the whole
+// database transaction can safely be run again, or returns "" otherwise.
+func RetryableCause(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+
+	switch pgErr.Code {
+	case "40P01":
+		return "deadlock"
+	case "40001":
+		return "serialization_failure"
+	}
+	return ""
+}
+
+// IsUniqueViolation reports whether err is postgres refusing a row that
+// breaks the named unique constraint.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
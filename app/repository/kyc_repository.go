@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"backend-path/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IKycRepository interface {
+	Create(submission *models.KycSubmission) error
+	FindByID(id uuid.UUID) (*models.KycSubmission, error)
+	FindByUserID(userID uuid.UUID) ([]models.KycSubmission, error)
+	FindAll(status models.KycStatus, limit, offset int) ([]models.KycSubmission, int64, error)
+	HasPending(userID uuid.UUID) bool
+	FindErasedWithDocument(userID *uuid.UUID, limit int) ([]models.KycSubmission, error)
+	ClearDocument(id uuid.UUID) error
+	Review(submission *models.KycSubmission, tier *models.KycTier) error
+}
+
+type KycRepository struct{}
+
+func NewKycRepository() *KycRepository {
+	return &KycRepository{}
+}
+
+func (r *KycRepository) Create(submission *models.KycSubmission) error {
+	return DB.Create(submission).Error
+}
+
+func (r *KycRepository) FindByID(id uuid.UUID) (*models.KycSubmission, error) {
+	var submission models.KycSubmission
+	if err := DB.Preload("User").Where("id = ?", id).First(&submission).Error; err != nil {
+		return nil, err
+	}
+
+	return &submission, nil
+}
+
+func (r *KycRepository) FindByUserID(userID uuid.UUID) ([]models.KycSubmission, error) {
+	var submissions []models.KycSubmission
+	err := DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&submissions).Error
+
+	return submissions, err
+}
+
+// FindErasedWithDocument returns erased submissions whose document was not
+// deleted yet, of one user or of any when userID is nil.
+func (r *KycRepository) FindErasedWithDocument(userID *uuid.UUID, limit int) ([]models.KycSubmission, error) {
+	query := DB.Where("erased_at IS NOT NULL AND document_blob_key <> ''")
+
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var submissions []models.KycSubmission
+	err := query.Order("erased_at").Limit(limit).Find(&submissions).Error
+
+	return submissions, err
+}
+
+// ClearDocument unlinks the document of a submission once it was deleted
+// from the blob store.
+func (r *KycRepository) ClearDocument(id uuid.UUID) error {
+	return DB.Model(&models.KycSubmission{}).Where("id = ?", id).Update("document_blob_key", "").Error
+}
+
+func (r *KycRepository) FindAll(status models.KycStatus, limit, offset int) ([]models.KycSubmission, int64, error) {
+	var submissions []models.KycSubmission
+	var total int64
+
+	query := DB.Model(&models.KycSubmission{})
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Order("created_at").
+		Limit(limit).
+		Offset(offset).
+		Find(&submissions).Error
+
+	return submissions, total, err
+}
+
+func (r *KycRepository) HasPending(userID uuid.UUID) bool {
+	var total int64
+	DB.Model(&models.KycSubmission{}).
+		Where("user_id = ? AND status = ?", userID, models.KycStatusPending).
+		Count(&total)
+
+	return total > 0
+}
+
+// Review stores the decision and, for approvals, raises the user's tier in
+// the same transaction. The pending condition guards against two reviewers
+// deciding the same submission.
+func (r *KycRepository) Review(submission *models.KycSubmission, tier *models.KycTier) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.KycSubmission{}).
+			Where("id = ? AND status = ?", submission.ID, models.KycStatusPending).
+			Updates(map[string]interface{}{
+				"status":      submission.Status,
+				"reviewer_id": submission.ReviewerID,
+				"review_note": submission.ReviewNote,
+				"reviewed_at": submission.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if tier == nil {
+			return nil
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ? AND kyc_tier < ?", submission.UserID, *tier).
+			Update("kyc_tier", *tier).Error
+	})
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "backend-path/app/models"
+
+type IPermissionRepository interface {
+	FindAll() ([]models.Permission, error)
+	FindByNames(names []string) ([]models.Permission, error)
+}
+
+type PermissionRepository struct{}
+
+func NewPermissionRepository() *PermissionRepository {
+	return &PermissionRepository{}
+}
+
+func (r *PermissionRepository) FindAll() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := DB.Order("name ASC").Find(&permissions).Error
+
+	return permissions, err
+}
+
+func (r *PermissionRepository) FindByNames(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+
+	err := DB.Where("name IN ?", names).Find(&permissions).Error
+
+	return permissions, err
+}
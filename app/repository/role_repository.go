@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"backend-path/app/models"
+
+	"gorm.io/gorm"
+)
+
+type IRoleRepository interface {
+	FindAll() ([]models.RoleDefinition, error)
+	FindByID(id models.Role) (*models.RoleDefinition, error)
+	FindByName(name string) (*models.RoleDefinition, error)
+	Create(role *models.RoleDefinition) error
+	Update(role *models.RoleDefinition) error
+	Delete(id models.Role) error
+	CountUsers(id models.Role) (int64, error)
+	PermissionNames(id models.Role) ([]string, error)
+}
+
+type RoleRepository struct{}
+
+func NewRoleRepository() *RoleRepository {
+	return &RoleRepository{}
+}
+
+func (r *RoleRepository) FindAll() ([]models.RoleDefinition, error) {
+	var roles []models.RoleDefinition
+	err := DB.Preload("Permissions").Order("id ASC").Find(&roles).Error
+
+	return roles, err
+}
+
+func (r *RoleRepository) FindByID(id models.Role) (*models.RoleDefinition, error) {
+	var role models.RoleDefinition
+	if err := DB.Preload("Permissions").Where("id = ?", id).First(&role).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepository) FindByName(name string) (*models.RoleDefinition, error) {
+	var role models.RoleDefinition
+	if err := DB.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepository) Create(role *models.RoleDefinition) error {
+	return DB.Create(role).Error
+}
+
+func (r *RoleRepository) Update(role *models.RoleDefinition) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(role).Association("Permissions").Replace(role.Permissions)
+	})
+}
+
+func (r *RoleRepository) Delete(id models.Role) error {
+	return DB.Delete(&models.RoleDefinition{}, "id = ?", id).Error
+}
+
+// CountUsers includes soft deleted users, they keep their role and can be
+// restored.
+func (r *RoleRepository) CountUsers(id models.Role) (int64, error) {
+	var total int64
+	err := DB.Unscoped().Model(&models.User{}).Where("role_id = ?", id).Count(&total).Error
+
+	return total, err
+}
+
+func (r *RoleRepository) PermissionNames(id models.Role) ([]string, error) {
+	var names []string
+	err := DB.Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id = ?", id).
+		Pluck("permissions.name", &names).Error
+
+	return names, err
+}
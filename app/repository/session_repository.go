@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ISessionRepository interface {
+	Create(session *models.Session) error
+	FindByID(id uuid.UUID) (*models.Session, error)
+	FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.Session, error)
+	FindActiveByUserID(userID uuid.UUID) ([]models.Session, error)
+	FindByUserID(userID uuid.UUID) ([]models.Session, error)
+	Revoke(id uuid.UUID) error
+	RevokeAllByUserID(userID uuid.UUID) ([]uuid.UUID, error)
+	TouchActivity(id uuid.UUID, activityAt time.Time) error
+}
+
+type SessionRepository struct{}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	return DB.Create(session).Error
+}
+
+func (r *SessionRepository) FindByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := DB.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) FindByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := DB.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) FindActiveByUserID(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_activity_at DESC").
+		Find(&sessions).Error
+
+	return sessions, err
+}
+
+func (r *SessionRepository) FindByUserID(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := DB.Where("user_id = ?", userID).Order("created_at").Find(&sessions).Error
+
+	return sessions, err
+}
+
+func (r *SessionRepository) Revoke(id uuid.UUID) error {
+	return DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *SessionRepository) RevokeAllByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+
+	err = DB.Model(&models.Session{}).
+		Where("id IN ?", ids).
+		Update("revoked_at", time.Now()).Error
+
+	return ids, err
+}
+
+func (r *SessionRepository) TouchActivity(id uuid.UUID, activityAt time.Time) error {
+	return DB.Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("last_activity_at", activityAt).Error
+}
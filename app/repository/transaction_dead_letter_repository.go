@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITransactionDeadLetterRepository interface {
+	Create(ctx context.Context, deadLetter *models.TransactionDeadLetter) error
+	FindByID(id uuid.UUID) (*models.TransactionDeadLetter, error)
+	FindByIDForUpdate(tx *gorm.DB, id uuid.UUID) (*models.TransactionDeadLetter, error)
+	FindAll(status models.DeadLetterStatus, limit, offset int) ([]models.TransactionDeadLetter, int64, error)
+	Resolve(tx *gorm.DB, deadLetter *models.TransactionDeadLetter) error
+	CountPending() (int64, error)
+	GetDB() *gorm.DB
+}
+
+type TransactionDeadLetterRepository struct{}
+
+func NewTransactionDeadLetterRepository() *TransactionDeadLetterRepository {
+	return &TransactionDeadLetterRepository{}
+}
+
+func (r *TransactionDeadLetterRepository) Create(ctx context.Context, deadLetter *models.TransactionDeadLetter) error {
+	return DB.WithContext(ctx).Create(deadLetter).Error
+}
+
+func (r *TransactionDeadLetterRepository) FindByID(id uuid.UUID) (*models.TransactionDeadLetter, error) {
+	var deadLetter models.TransactionDeadLetter
+	if err := DB.Where("id = ?", id).First(&deadLetter).Error; err != nil {
+		return nil, err
+	}
+
+	return &deadLetter, nil
+}
+
+func (r *TransactionDeadLetterRepository) FindByIDForUpdate(tx *gorm.DB, id uuid.UUID) (*models.TransactionDeadLetter, error) {
+	var deadLetter models.TransactionDeadLetter
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&deadLetter).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &deadLetter, nil
+}
+
+func (r *TransactionDeadLetterRepository) FindAll(status models.DeadLetterStatus, limit, offset int) ([]models.TransactionDeadLetter, int64, error) {
+	var deadLetters []models.TransactionDeadLetter
+	var total int64
+
+	query := DB.Model(&models.TransactionDeadLetter{})
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deadLetters).Error
+
+	return deadLetters, total, err
+}
+
+// Resolve stores the replay or discard decision. The caller holds the row
+// lock taken by FindByIDForUpdate.
+func (r *TransactionDeadLetterRepository) Resolve(tx *gorm.DB, deadLetter *models.TransactionDeadLetter) error {
+	resolvedAt := time.Now()
+	deadLetter.ResolvedAt = &resolvedAt
+
+	return tx.Model(deadLetter).Updates(map[string]interface{}{
+		"status":                  deadLetter.Status,
+		"replayed_transaction_id": deadLetter.ReplayedTransactionID,
+		"resolved_by":             deadLetter.ResolvedBy,
+		"resolved_at":             deadLetter.ResolvedAt,
+	}).Error
+}
+
+func (r *TransactionDeadLetterRepository) CountPending() (int64, error) {
+	var total int64
+	err := DB.Model(&models.TransactionDeadLetter{}).
+		Where("status = ?", models.DeadLetterPending).
+		Count(&total).Error
+
+	return total, err
+}
+
+func (r *TransactionDeadLetterRepository) GetDB() *gorm.DB {
+	return DB
+}
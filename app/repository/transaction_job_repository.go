@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ITransactionJobRepository interface {
+	Create(tx *gorm.DB, record *models.TransactionJobRecord) error
+	Claim(owner string, limit int, lease time.Duration) ([]models.TransactionJobRecord, error)
+	Delete(id uuid.UUID, owner string) error
+	Release(id uuid.UUID, owner string) error
+	Recover(owner string) (int64, error)
+}
+
+type TransactionJobRepository struct{}
+
+func NewTransactionJobRepository() *TransactionJobRepository {
+	return &TransactionJobRepository{}
+}
+
+func (r *TransactionJobRepository) Create(tx *gorm.DB, record *models.TransactionJobRecord) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	return tx.Create(record).Error
+}
+
+// Claim locks the oldest queued jobs for owner, skipping jobs other
+// instances are claiming at the same time. Jobs whose lease ran out are
+// claimable again.
+func (r *TransactionJobRepository) Claim(owner string, limit int, lease time.Duration) ([]models.TransactionJobRecord, error) {
+	var records []models.TransactionJobRecord
+	err := DB.Raw(`
+		UPDATE transaction_jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM transaction_jobs
+			WHERE status = ? OR (status = ? AND locked_until < now())
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.TxJobProcessing, owner, time.Now().Add(lease),
+		models.TxJobQueued, models.TxJobProcessing,
+		limit,
+	).Scan(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records, nil
+}
+
+// Delete removes a finished job, as long as owner still holds it. A job
+// whose lease ran out may have been claimed by another instance meanwhile.
+func (r *TransactionJobRepository) Delete(id uuid.UUID, owner string) error {
+	result := DB.Delete(&models.TransactionJobRecord{}, "id = ? AND locked_by = ?", id, owner)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrJobNotOwned
+	}
+	return nil
+}
+
+// Release puts a job owner holds back into the queue.
+func (r *TransactionJobRepository) Release(id uuid.UUID, owner string) error {
+	result := DB.Model(&models.TransactionJobRecord{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, models.TxJobProcessing, owner).
+		Updates(map[string]interface{}{
+			"status":       models.TxJobQueued,
+			"locked_by":    nil,
+			"locked_until": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrJobNotOwned
+	}
+	return nil
+}
+
+// Recover requeues jobs that were in flight when their owner stopped: the
+// ones owner itself held before a restart and the ones whose lease expired.
+func (r *TransactionJobRepository) Recover(owner string) (int64, error) {
+	result := DB.Model(&models.TransactionJobRecord{}).
+		Where("status = ? AND (locked_by = ? OR locked_until < now())", models.TxJobProcessing, owner).
+		Updates(map[string]interface{}{
+			"status":       models.TxJobQueued,
+			"locked_by":    nil,
+			"locked_until": nil,
+		})
+
+	return result.RowsAffected, result.Error
+}
@@ -2,16 +2,24 @@ package repository
 
 import (
 	"backend-path/app/models"
+	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ITransactionRepository interface {
 	Create(tx *gorm.DB, transaction *models.Transaction) error
-	FindByID(id uuid.UUID) (*models.Transaction, error)
-	FindByUserID(userID uuid.UUID, limit, offset int) ([]models.Transaction, int64, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error)
+	FindByIDForUpdate(tx *gorm.DB, id uuid.UUID) (*models.Transaction, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.Transaction, int64, error)
 	Update(tx *gorm.DB, transaction *models.Transaction) error
+	MarkFailed(tx *gorm.DB, id uuid.UUID, reason string) (*models.Transaction, error)
+	FindAllByUserID(userID uuid.UUID) ([]models.Transaction, error)
+	CountPendingByUserID(tx *gorm.DB, userID uuid.UUID) (int64, error)
+	SumOutgoingSince(tx *gorm.DB, userID uuid.UUID, since time.Time, statuses ...models.TransactionStatus) (float64, error)
 	GetDB() *gorm.DB
 }
 
@@ -29,18 +37,27 @@ func (r *TransactionRepository) Create(tx *gorm.DB, transaction *models.Transact
 	return tx.Create(transaction).Error
 }
 
-func (r *TransactionRepository) FindByID(id uuid.UUID) (*models.Transaction, error) {
+func (r *TransactionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := DB.Preload("FromUser").Preload("ToUser").Where("id = ?", id).First(&transaction).Error
+	err := DB.WithContext(ctx).Preload("FromUser").Preload("ToUser").Where("id = ?", id).First(&transaction).Error
 
 	return &transaction, err
 }
 
-func (r *TransactionRepository) FindByUserID(userID uuid.UUID, limit, offset int) ([]models.Transaction, int64, error) {
+func (r *TransactionRepository) FindByIDForUpdate(tx *gorm.DB, id uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&transaction).Error
+
+	return &transaction, err
+}
+
+func (r *TransactionRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.Transaction, int64, error) {
 	var transactions []models.Transaction
 	var total int64
 
-	query := DB.Model(&models.Transaction{}).Where("from_user_id = ? OR to_user_id = ?", userID, userID)
+	query := DB.WithContext(ctx).Model(&models.Transaction{}).Where("from_user_id = ? OR to_user_id = ?", userID, userID)
 
 	query.Count(&total)
 
@@ -61,6 +78,61 @@ func (r *TransactionRepository) Update(tx *gorm.DB, transaction *models.Transact
 	return tx.Save(transaction).Error
 }
 
+// MarkFailed fails a transaction that is still pending, outside of the
+// rolled back processing transaction. It returns nil when the transaction
+// was no longer pending.
+func (r *TransactionRepository) MarkFailed(tx *gorm.DB, id uuid.UUID, reason string) (*models.Transaction, error) {
+	if tx == nil {
+		tx = DB
+	}
+
+	var transaction models.Transaction
+	result := tx.Model(&transaction).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, models.TxStatusPending).
+		Updates(map[string]interface{}{
+			"status":         models.TxStatusFailed,
+			"failure_reason": reason,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &transaction, nil
+}
+
+func (r *TransactionRepository) FindAllByUserID(userID uuid.UUID) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := DB.Where("from_user_id = ? OR to_user_id = ?", userID, userID).
+		Order("created_at").
+		Find(&transactions).Error
+
+	return transactions, err
+}
+
+func (r *TransactionRepository) CountPendingByUserID(tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	var total int64
+	err := DB.Model(&models.Transaction{}).
+		Where("(from_user_id = ? OR to_user_id = ?) AND status = ?", userID, userID, models.TxStatusPending).
+		Count(&total).Error
+
+	return total, err
+}
+
+// SumOutgoingSince adds up what left the account since the given time in
+// transactions with one of the given statuses.
+func (r *TransactionRepository) SumOutgoingSince(tx *gorm.DB, userID uuid.UUID, since time.Time, statuses ...models.TransactionStatus) (float64, error) {
+	if tx == nil {
+		tx = DB
+	}
+
+	var total float64
+	err := tx.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("from_user_id = ? AND status IN ? AND created_at >= ?", userID, statuses, since).
+		Scan(&total).Error
+
+	return total, err
+}
+
 func (r *TransactionRepository) GetDB() *gorm.DB {
 	return DB
 }
\ No newline at end of file
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"backend-path/app/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ITransactionStatsRepository interface {
+	Record(tx *gorm.DB, transactionID uuid.UUID, createdAt time.Time, txType models.TransactionType, status models.TransactionStatus, amount float64) error
+	Retract(tx *gorm.DB, transactionID uuid.UUID, createdAt time.Time, txType models.TransactionType, status models.TransactionStatus, amount float64) error
+	Aggregate(ctx context.Context, filter TransactionStatsFilter) ([]models.TransactionStatsHourly, error)
+}
+
+// TransactionStatsFilter selects the hours in [From, To), grouped by
+// Interval, "hour" or "day". A zero Type matches every type.
+type TransactionStatsFilter struct {
+	From     time.Time
+	To       time.Time
+	Type     models.TransactionType
+	Interval string
+}
+
+type TransactionStatsRepository struct{}
+
+func NewTransactionStatsRepository() *TransactionStatsRepository {
+	return &TransactionStatsRepository{}
+}
+
+// Record counts a transaction that reached a final status. Call it within
+// the database transaction that stores the status, so the counters never
+// drift from the transactions table.
+func (r *TransactionStatsRepository) Record(tx *gorm.DB, transactionID uuid.UUID, createdAt time.Time, txType models.TransactionType, status models.TransactionStatus, amount float64) error {
+	return r.add(tx, transactionID, createdAt, txType, status, 1, amount)
+}
+
+// Retract takes back what Record counted, for a failed transaction that is
+// queued again.
+func (r *TransactionStatsRepository) Retract(tx *gorm.DB, transactionID uuid.UUID, createdAt time.Time, txType models.TransactionType, status models.TransactionStatus, amount float64) error {
+	return r.add(tx, transactionID, createdAt, txType, status, -1, -amount)
+}
+
+func (r *TransactionStatsRepository) add(tx *gorm.DB, transactionID uuid.UUID, createdAt time.Time, txType models.TransactionType, status models.TransactionStatus, count int64, amount float64) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	slot := int(transactionID[len(transactionID)-1]) % models.TransactionStatsSlots
+
+	return tx.Exec(`
+		INSERT INTO transaction_stats_hourly (bucket, type, status, slot, count, amount, updated_at)
+		VALUES (date_trunc('hour', ?::timestamptz), ?, ?, ?, ?, ?, now())
+		ON CONFLICT (bucket, type, status, slot)
+		DO UPDATE SET count = transaction_stats_hourly.count + EXCLUDED.count,
+			amount = transaction_stats_hourly.amount + EXCLUDED.amount,
+			updated_at = now()`,
+		createdAt, txType, status, slot, count, amount,
+	).Error
+}
+
+func (r *TransactionStatsRepository) Aggregate(ctx context.Context, filter TransactionStatsFilter) ([]models.TransactionStatsHourly, error) {
+	var stats []models.TransactionStatsHourly
+
+	query := DB.WithContext(ctx).Model(&models.TransactionStatsHourly{}).
+		Select("date_trunc(?, bucket) AS bucket, type, status, SUM(count) AS count, SUM(amount) AS amount", filter.Interval).
+		Where("bucket >= ? AND bucket < ?", filter.From, filter.To)
+
+	if filter.Type != 0 {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	err := query.Group("1, type, status").Order("1, type, status").Scan(&stats).Error
+	return stats, err
+}
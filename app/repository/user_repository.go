@@ -2,18 +2,31 @@ package repository
 
 import (
 	"backend-path/app/models"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IUserRepository interface {
 	Insert(user *models.User) error
 	FindByEmail(email string) (*models.User, error)
 	FindByID(id uuid.UUID) (*models.User, error)
-	FindAll(limit, offset int) ([]models.User, int64, error)
+	FindByIDForShare(tx *gorm.DB, id uuid.UUID) (*models.User, error)
+	FindAll(filter UserFilter, limit, offset int) ([]models.User, int64, error)
 	Update(user *models.User) error
+	Save(tx *gorm.DB, user *models.User) error
 	Delete(id uuid.UUID) error
 	IsExist(email string) bool
+	IsUsernameExist(username string) bool
+	FindDeletedByID(id uuid.UUID) (*models.User, error)
+	Restore(id uuid.UUID) error
+	FindPurgeable(deletedBefore time.Time, limit int) ([]models.User, error)
+	Purge(id uuid.UUID) error
+	Erase(tx *gorm.DB, user *models.User, originalEmail string) error
 }
 
 type UserRepository struct{}
@@ -22,13 +35,41 @@ func NewUserRepository() *UserRepository {
 	return &UserRepository{}
 }
 
-func (r *UserRepository) FindAll(limit, offset int) ([]models.User, int64, error) {
+type UserFilter struct {
+	RoleID      *models.Role
+	Status      models.UserStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Query       string
+	Sort        string
+	Desc        bool
+}
+
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"username":   "username",
+	"email":      "email",
+}
+
+func (r *UserRepository) FindAll(filter UserFilter, limit, offset int) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
-	DB.Model(&models.User{}).Count(&total)
+	if err := r.applyFilter(DB.Model(&models.User{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := userSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	direction := " ASC"
+	if filter.Desc {
+		direction = " DESC"
+	}
 
-	err := DB.Limit(limit).Offset(offset).Order("created_at DESC").Find(&users).Error
+	err := r.applyFilter(DB, filter).Limit(limit).Offset(offset).Order(column + direction).Order("id" + direction).Find(&users).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -36,6 +77,44 @@ func (r *UserRepository) FindAll(limit, offset int) ([]models.User, int64, error
 	return users, total, nil
 }
 
+// applyFilter builds the list conditions. Status matches the effective
+// status, a freeze or suspension past its expiry counts as active.
+func (r *UserRepository) applyFilter(query *gorm.DB, filter UserFilter) *gorm.DB {
+	if filter.RoleID != nil {
+		query = query.Where("role_id = ?", *filter.RoleID)
+	}
+
+	switch filter.Status {
+	case 0:
+	case models.UserStatusActive:
+		query = query.Where("(status = ? OR (status IN ? AND status_expires_at <= NOW()))",
+			models.UserStatusActive, []models.UserStatus{models.UserStatusFrozen, models.UserStatusSuspended})
+	case models.UserStatusFrozen, models.UserStatusSuspended:
+		query = query.Where("status = ? AND (status_expires_at IS NULL OR status_expires_at > NOW())", filter.Status)
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("(username ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+
+	return query
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 func (r *UserRepository) Insert(user *models.User) error {
 	if err := DB.Create(&user).Error; err != nil {
 		return err
@@ -52,6 +131,14 @@ func (r *UserRepository) Update(user *models.User) error {
 	return nil
 }
 
+func (r *UserRepository) Save(tx *gorm.DB, user *models.User) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	return tx.Save(user).Error
+}
+
 func (r *UserRepository) Delete(id uuid.UUID) error {
 	if err := DB.Delete(&models.User{}, "id = ?", id).Error; err != nil {
 		return err
@@ -78,6 +165,17 @@ func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByIDForShare reads the user within tx and keeps its status from
+// changing until tx ends.
+func (r *UserRepository) FindByIDForShare(tx *gorm.DB, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) IsExist(email string) bool {
 	var user models.User
 	if err := DB.Where("email = ?", email).First(&user).Error; err != nil {
@@ -85,4 +183,162 @@ func (r *UserRepository) IsExist(email string) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+func (r *UserRepository) IsUsernameExist(username string) bool {
+	var user models.User
+	if err := DB.Where("username = ?", username).First(&user).Error; err != nil {
+		return false
+	}
+
+	return true
+}
+
+func (r *UserRepository) FindDeletedByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) Restore(id uuid.UUID) error {
+	return DB.Unscoped().Model(&models.User{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+// FindPurgeable returns soft deleted users past retention that have no
+// financial footprint: no transactions and no money left on a balance. Users
+// with kyc submissions, their own or reviewed ones, are kept along with the
+// kyc record, their foreign keys would block the purge.
+func (r *UserRepository) FindPurgeable(deletedBefore time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM transactions t WHERE t.from_user_id = users.id OR t.to_user_id = users.id)").
+		Where("NOT EXISTS (SELECT 1 FROM balances b WHERE b.user_id = users.id AND b.amount <> 0)").
+		Where("NOT EXISTS (SELECT 1 FROM kyc_submissions k WHERE k.user_id = users.id OR k.reviewer_id = users.id)").
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+func (r *UserRepository) Purge(id uuid.UUID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND amount = 0", id).Delete(&models.Balance{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Delete(&models.User{}).Error
+	})
+}
+
+// Erase stores the pseudonymized user and scrubs personal data recorded
+// about it elsewhere: identifying keys in audit log details and changes
+// (including failed logins that only carry the email), the ip of entries it
+// caused, device data on sessions and the identity data of kyc submissions,
+// of which pending ones are rejected. Financial records keep referencing the
+// user id and are left untouched. Events still waiting in the audit outbox
+// are chained first, so the scrub covers them. Only entries that hold
+// personal data are scrubbed, and they are listed in a redaction entry on
+// the user's chain, so the verifier can tell an erasure from tampering with
+// that data. Kyc documents stay linked until the caller deleted them from
+// the blob store.
+func (r *UserRepository) Erase(tx *gorm.DB, user *models.User, originalEmail string) error {
+	if tx == nil {
+		tx = DB
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+
+		if err := NewAuditOutboxRepository().RelayAll(tx); err != nil {
+			return err
+		}
+
+		piiKeys := "{" + strings.Join(models.AuditPiiDetailKeys, ",") + "}"
+		redactionID := uuid.New()
+		var scrubbed []struct {
+			ID         uuid.UUID
+			RedactedBy uuid.UUID
+		}
+
+		err := tx.Raw(`UPDATE audit_logs
+			SET details = details - ?::text[],
+				changes = NULL,
+				ip = NULL,
+				redacted_at = COALESCE(redacted_at, now()),
+				redacted_by = COALESCE(redacted_by, ?)
+			WHERE (entity_id = ? OR lower(details->>'email') = lower(?))
+				AND (jsonb_exists_any(details, ?::text[]) OR changes IS NOT NULL OR ip IS NOT NULL)
+			RETURNING id, redacted_by`,
+			piiKeys, redactionID, user.ID, originalEmail, piiKeys).Scan(&scrubbed).Error
+		if err != nil {
+			return err
+		}
+
+		var scrubbedActor []struct {
+			ID         uuid.UUID
+			RedactedBy uuid.UUID
+		}
+		err = tx.Raw(`UPDATE audit_logs
+			SET ip = NULL,
+				redacted_at = COALESCE(redacted_at, now()),
+				redacted_by = COALESCE(redacted_by, ?)
+			WHERE actor_user_id = ? AND ip IS NOT NULL
+			RETURNING id, redacted_by`, redactionID, user.ID).Scan(&scrubbedActor).Error
+		if err != nil {
+			return err
+		}
+
+		// entries erased before keep their earlier redaction
+		seen := make(map[uuid.UUID]bool)
+		redaction := models.AuditRedaction{Entries: []uuid.UUID{}}
+		for _, entry := range append(scrubbed, scrubbedActor...) {
+			if entry.RedactedBy == redactionID && !seen[entry.ID] {
+				seen[entry.ID] = true
+				redaction.Entries = append(redaction.Entries, entry.ID)
+			}
+		}
+
+		if len(redaction.Entries) > 0 {
+			details, _ := json.Marshal(redaction)
+			err := NewAuditRepository().Create(tx, &models.AuditLog{
+				ID:         redactionID,
+				EntityType: models.EntityUser,
+				EntityID:   user.ID,
+				Action:     models.ActionRedact,
+				Details:    string(details),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.Session{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"device": "", "ip": "", "user_agent": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.KycSubmission{}).
+			Where("user_id = ? AND erased_at IS NULL", user.ID).
+			Updates(map[string]interface{}{
+				"status":          gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", models.KycStatusPending, models.KycStatusRejected),
+				"full_name":       "",
+				"date_of_birth":   nil,
+				"document_number": "",
+				"review_note":     nil,
+				"erased_at":       time.Now(),
+			}).Error
+	})
+}
@@ -1,35 +1,57 @@
 package server
 
 import (
+	"backend-path/app/audit"
+	"backend-path/app/lifecycle"
+	"backend-path/app/services"
 	"backend-path/app/tracing"
 	"backend-path/utils"
 	"context"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/storage/redis"
 	"gorm.io/gorm"
 )
 
 type Server struct {
 	App *fiber.App
 	DB *gorm.DB
+	Redis *redis.Storage
+	done chan struct{}
 }
 
-func New(app *fiber.App, db *gorm.DB) *Server {
+func New(app *fiber.App, db *gorm.DB, redisStorage *redis.Storage) *Server {
 	return &Server{
 		App: app,
 		DB: db,
+		Redis: redisStorage,
+		done: make(chan struct{}),
 	}
 }
 
 func (s *Server) Start(port string) error {
+	s.registerShutdown()
 	s.gracefulShutdown()
 
+	s.App.Hooks().OnListen(func(fiber.ListenData) error {
+		lifecycle.SetReady()
+		return nil
+	})
+
 	utils.Logger.Info("Server is running on port " + port)
-	return s.App.Listen(":" + port)
+	if err := s.App.Listen(":" + port); err != nil {
+		return err
+	}
+
+	// Listen returns as soon as the shutdown stopped taking requests, the
+	// remaining steps must finish before the process exits
+	<-s.done
+	return nil
 }
 
 func (s *Server) gracefulShutdown() {
@@ -40,25 +62,74 @@ func (s *Server) gracefulShutdown() {
 
 		utils.Logger.Info("Shutting down server...")
 
-		if err := s.App.Shutdown(); err != nil {
-			utils.Logger.Error("Error shutting down server: " + err.Error())
-		}
+		lifecycle.Shutdown(readinessDelay())
 
-		s.cleanup()
+		utils.Logger.Info("Server shutdown complete")
+		close(s.done)
 	}()
 }
 
-func (s *Server) cleanup() {
-	if s.DB != nil {
-		if sqlDB, err := s.DB.DB(); err == nil {
-			sqlDB.Close()
-			utils.Logger.Info("Database connection closed")
-		}
+// readinessDelay is how long the instance keeps serving while reporting not
+// ready, SHUTDOWN_READINESS_DELAY_SECONDS and 5 seconds by default, enough
+// for load balancers polling readiness to stop routing to it. Set it to 0
+// to skip the wait, e.g. when nothing polls readiness.
+func readinessDelay() time.Duration {
+	value, ok := os.LookupEnv("SHUTDOWN_READINESS_DELAY_SECONDS")
+	if !ok || value == "" {
+		return 5 * time.Second
+	}
+
+	seconds, _ := strconv.Atoi(value)
+	return time.Duration(seconds) * time.Second
+}
+
+// registerShutdown lines up the server's own shutdown steps: requests stop
+// first so no new transactions arrive, the transaction workers drain before
+// the audit outbox flushes the events they recorded, and Redis and the
+// database close last.
+func (s *Server) registerShutdown() {
+	httpTimeout, _ := strconv.Atoi(os.Getenv("SHUTDOWN_HTTP_TIMEOUT_SECONDS"))
+	if httpTimeout == 0 {
+		httpTimeout = 10
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	tracing.ShutdownGlobal(ctx)
+	lifecycle.OnShutdown(lifecycle.PhaseIngress, "http", func() error {
+		return s.App.ShutdownWithTimeout(time.Duration(httpTimeout) * time.Second)
+	})
 
-	utils.Logger.Info("Server shutdown complete")
-}
\ No newline at end of file
+	lifecycle.OnShutdown(lifecycle.PhaseWorkers, "transaction-workers", func() error {
+		services.StopTransactionWorkers()
+		return nil
+	})
+
+	lifecycle.OnShutdown(lifecycle.PhaseFlush, "audit-outbox", func() error {
+		audit.StopOutbox()
+		return nil
+	})
+
+	lifecycle.OnShutdown(lifecycle.PhaseFlush, "telemetry", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		tracing.ShutdownGlobal(ctx)
+		return nil
+	})
+
+	lifecycle.OnShutdown(lifecycle.PhaseClose, "redis", func() error {
+		if s.Redis == nil {
+			return nil
+		}
+		return s.Redis.Close()
+	})
+
+	lifecycle.OnShutdown(lifecycle.PhaseClose, "database", func() error {
+		if s.DB == nil {
+			return nil
+		}
+
+		sqlDB, err := s.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+}
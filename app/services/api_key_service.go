@@ -0,0 +1,137 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/transformer"
+	"backend-path/utils"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IApiKeyService interface {
+	Create(ctx *fiber.Ctx, userID uuid.UUID, req dto.CreateApiKeyRequest) error
+	List(ctx *fiber.Ctx, userID uuid.UUID) error
+	Revoke(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error
+}
+
+const apiKeyCreateAttempts = 3
+
+type ApiKeyService struct {
+	apiKeyRepo  repository.IApiKeyRepository
+	auditOutbox audit.IOutbox
+}
+
+func NewApiKeyService() *ApiKeyService {
+	return &ApiKeyService{
+		apiKeyRepo:  repository.NewApiKeyRepository(),
+		auditOutbox: audit.NewOutbox(),
+	}
+}
+
+func (s *ApiKeyService) Create(ctx *fiber.Ctx, userID uuid.UUID, req dto.CreateApiKeyRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	apiKey := &models.ApiKey{
+		UserID: userID,
+		Name:   req.Name,
+		Scopes: strings.Join(uniqueScopes(req.Scopes), ","),
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	// a prefix taken by another key gets a fresh key
+	var key string
+	for attempt := 1; ; attempt++ {
+		var err error
+		key, apiKey.Prefix, err = utils.GenerateApiKey()
+		if err != nil {
+			return utils.JsonErrorInternal(ctx, err, "E_API_KEY_GENERATE")
+		}
+		apiKey.KeyHash = utils.HashApiKey(key)
+
+		err = s.apiKeyRepo.Create(apiKey)
+		if err == nil {
+			break
+		}
+		if attempt == apiKeyCreateAttempts || !repository.IsUniqueViolation(err, "api_keys_prefix_unique") {
+			return utils.JsonErrorInternal(ctx, err, "E_API_KEY_CREATE")
+		}
+	}
+
+	s.logApiKey(ctx, apiKey, models.ActionCreate)
+
+	return utils.JsonSuccess(ctx, dto.ApiKeyCreatedResponse{
+		ApiKeyResponse: transformer.ApiKeyTransformer(apiKey),
+		Key:            key,
+	})
+}
+
+func (s *ApiKeyService) List(ctx *fiber.Ctx, userID uuid.UUID) error {
+	apiKeys, err := s.apiKeyRepo.FindByUserID(userID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_API_KEY_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, transformer.ApiKeyListTransformer(apiKeys))
+}
+
+func (s *ApiKeyService) Revoke(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error {
+	apiKey, err := s.apiKeyRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("api key not found"))
+	}
+
+	if apiKey.IsRevoked() {
+		return utils.JsonError(ctx, errors.New("api key already revoked"), "E_API_KEY_REVOKED")
+	}
+
+	if err := s.apiKeyRepo.Revoke(apiKey.ID); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_API_KEY_REVOKE")
+	}
+
+	s.logApiKey(ctx, apiKey, models.ActionDelete)
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "api key revoked"})
+}
+
+func (s *ApiKeyService) logApiKey(ctx *fiber.Ctx, apiKey *models.ApiKey, action models.AuditAction) {
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"user_id": apiKey.UserID.String(),
+		"name":    apiKey.Name,
+		"prefix":  apiKey.Prefix,
+		"scopes":  apiKey.ScopeList(),
+	})
+
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityApiKey,
+		EntityID:     apiKey.ID,
+		Action:       action,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result
+}
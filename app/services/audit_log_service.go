@@ -0,0 +1,279 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/transformer"
+	"backend-path/constants"
+	"backend-path/utils"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IAuditLogService interface {
+	GetAll(ctx *fiber.Ctx, req dto.AuditLogFilterRequest) error
+	Timeline(ctx *fiber.Ctx, entityID uuid.UUID, req dto.AuditLogFilterRequest) error
+	Export(ctx *fiber.Ctx, req dto.AuditLogFilterRequest) error
+	VerifyChain(ctx *fiber.Ctx, req dto.AuditChainVerifyRequest) error
+}
+
+type AuditLogService struct {
+	auditRepo   repository.IAuditLogRepository
+	auditOutbox audit.IOutbox
+}
+
+const (
+	auditLogDefaultLimit = 50
+	auditLogExportBatch  = 1000
+)
+
+var auditDetailsKeyPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+func NewAuditLogService() *AuditLogService {
+	return &AuditLogService{
+		auditRepo:   repository.NewAuditRepository(),
+		auditOutbox: audit.NewOutbox(),
+	}
+}
+
+func (s *AuditLogService) GetAll(ctx *fiber.Ctx, req dto.AuditLogFilterRequest) error {
+	return s.page(ctx, req, false)
+}
+
+// Timeline lists everything recorded for one entity id in chronological
+// order. A user's timeline includes its balance entries, both share the id.
+func (s *AuditLogService) Timeline(ctx *fiber.Ctx, entityID uuid.UUID, req dto.AuditLogFilterRequest) error {
+	req.EntityID = entityID.String()
+	return s.page(ctx, req, true)
+}
+
+func (s *AuditLogService) page(ctx *fiber.Ctx, req dto.AuditLogFilterRequest, ascending bool) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	filter, cursor, err := s.auditLogFilter(req)
+	if err != nil {
+		return utils.JsonErrorValidation(ctx, err)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = auditLogDefaultLimit
+	}
+
+	logs, err := s.auditRepo.Find(filter, cursor, limit, ascending)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_AUDIT_LOG_LIST")
+	}
+
+	response := dto.AuditLogPageResponse{
+		Data: transformer.AuditLogListTransformer(logs),
+	}
+
+	if len(logs) == limit {
+		last := logs[len(logs)-1]
+		response.NextCursor = repository.AuditLogCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return utils.JsonSuccess(ctx, response)
+}
+
+// Export streams matching entries as CSV, newest first, capped at
+// AUDIT_EXPORT_MAX_ROWS rows. The export itself is audited.
+func (s *AuditLogService) Export(ctx *fiber.Ctx, req dto.AuditLogFilterRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	filter, cursor, err := s.auditLogFilter(req)
+	if err != nil {
+		return utils.JsonErrorValidation(ctx, err)
+	}
+
+	maxRows, _ := strconv.Atoi(os.Getenv("AUDIT_EXPORT_MAX_ROWS"))
+	if maxRows == 0 {
+		maxRows = 100000
+	}
+
+	actorID, _ := uuid.Parse(ctx.Locals("user_auth").(string))
+	filterJSON, _ := json.Marshal(req)
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"resource": "audit_logs",
+		"filter":   json.RawMessage(filterJSON),
+	})
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     actorID,
+		Action:       models.ActionExport,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+
+	filename := "audit-logs-" + time.Now().Format("20060102-150405") + ".csv"
+	ctx.Set(fiber.HeaderContentType, "text/csv")
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"id", "created_at", "entity_type", "entity_id", "action", "actor_user_id", "actor_role", "request_id", "ip", "details", "changes"})
+
+		written := 0
+		for written < maxRows {
+			batch := auditLogExportBatch
+			if maxRows-written < batch {
+				batch = maxRows - written
+			}
+
+			logs, err := s.auditRepo.Find(filter, cursor, batch, false)
+			if err != nil {
+				utils.Logger.Error("❌ AUDIT LOG EXPORT ERROR: " + err.Error())
+				break
+			}
+
+			for _, log := range logs {
+				actorUserID := ""
+				if log.ActorUserID != nil {
+					actorUserID = log.ActorUserID.String()
+				}
+
+				writer.Write([]string{
+					log.ID.String(),
+					log.CreatedAt.Format(constants.TimestampFormat),
+					log.EntityType.String(),
+					log.EntityID.String(),
+					log.Action.String(),
+					actorUserID,
+					log.ActorRole,
+					log.RequestID,
+					log.IP,
+					log.Details,
+					log.Changes,
+				})
+			}
+
+			writer.Flush()
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			written += len(logs)
+			if len(logs) < batch {
+				break
+			}
+
+			last := logs[len(logs)-1]
+			cursor = &repository.AuditLogCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+	})
+
+	return nil
+}
+
+func (s *AuditLogService) VerifyChain(ctx *fiber.Ctx, req dto.AuditChainVerifyRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	var entityType models.EntityType
+	if req.EntityType != "" {
+		if entityType = parseEntityType(req.EntityType); entityType == 0 {
+			return utils.JsonErrorValidation(ctx, errors.New("unknown entity_type"))
+		}
+	}
+
+	var entityID *uuid.UUID
+	if req.EntityID != "" {
+		id := uuid.MustParse(req.EntityID)
+		entityID = &id
+	}
+
+	report, err := audit.VerifyChain(entityType, entityID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_AUDIT_CHAIN_VERIFY")
+	}
+
+	return utils.JsonSuccess(ctx, report)
+}
+
+func (s *AuditLogService) auditLogFilter(req dto.AuditLogFilterRequest) (repository.AuditLogFilter, *repository.AuditLogCursor, error) {
+	filter := repository.AuditLogFilter{}
+
+	if req.EntityType != "" {
+		if filter.EntityType = parseEntityType(req.EntityType); filter.EntityType == 0 {
+			return filter, nil, errors.New("unknown entity_type")
+		}
+	}
+
+	if req.Action != "" {
+		for action := models.ActionCreate; action.IsValid(); action++ {
+			if action.String() == req.Action {
+				filter.Action = action
+			}
+		}
+
+		if filter.Action == 0 {
+			return filter, nil, errors.New("unknown action")
+		}
+	}
+
+	if req.EntityID != "" {
+		entityID := uuid.MustParse(req.EntityID)
+		filter.EntityID = &entityID
+	}
+
+	if req.ActorID != "" {
+		actorID := uuid.MustParse(req.ActorID)
+		filter.ActorID = &actorID
+	}
+
+	filter.RequestID = req.RequestID
+
+	if req.From != "" {
+		from, _ := time.Parse(time.RFC3339, req.From)
+		filter.From = &from
+	}
+
+	if req.To != "" {
+		to, _ := time.Parse(time.RFC3339, req.To)
+		filter.To = &to
+	}
+
+	for key := range req.Details {
+		if !auditDetailsKeyPattern.MatchString(key) {
+			return filter, nil, errors.New("invalid details filter key " + key)
+		}
+	}
+	filter.Details = req.Details
+
+	if req.Cursor == "" {
+		return filter, nil, nil
+	}
+
+	cursor, err := repository.ParseAuditLogCursor(req.Cursor)
+	if err != nil {
+		return filter, nil, err
+	}
+
+	return filter, cursor, nil
+}
+
+func parseEntityType(name string) models.EntityType {
+	for entityType := models.EntityUser; entityType.IsValid(); entityType++ {
+		if entityType.String() == name {
+			return entityType
+		}
+	}
+	return 0
+}
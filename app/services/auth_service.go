@@ -1,16 +1,22 @@
 package services
 
 import (
+	"backend-path/app/audit"
 	"backend-path/app/dto"
+	"backend-path/app/metrics"
 	"backend-path/app/middlewares"
 	"backend-path/app/models"
 	"backend-path/app/repository"
+	"backend-path/configs"
 	"backend-path/constants"
 	"backend-path/utils"
 	"encoding/json"
 	"errors"
+	"math"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -23,61 +29,109 @@ type IAuthService interface {
 	Authenticate(ctx *fiber.Ctx, req dto.LoginRequest) error
 	Register(ctx *fiber.Ctx, req dto.RegisterRequest) error
 	RefreshToken(ctx *fiber.Ctx, userID uuid.UUID) error
+	Jwks(ctx *fiber.Ctx) error
 }
 
 type AuthService struct {
 	userRepo repository.IUserRepository
-	auditRepo repository.IAuditLogRepository
+	auditOutbox audit.IOutbox
+	loginAttempts ILoginAttemptService
+	sessionService ISessionService
 }
 
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
 func NewAuthService() *AuthService {
 	return &AuthService{
 		userRepo:  repository.NewUserRepository(),
-		auditRepo: repository.NewAuditRepository(),
+		auditOutbox: audit.NewOutbox(),
+		loginAttempts: NewLoginAttemptService(),
+		sessionService: NewSessionService(),
 	}
 }
 
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
 func (s *AuthService) Authenticate(ctx *fiber.Ctx, req dto.LoginRequest) error {
 	if errors := utils.ValidateStruct(req); errors != nil {
 		return utils.JsonErrorValidationFields(ctx, errors)
 	}
 
-	user, err := s.userRepo.FindByEmail(req.Email);
+	email := NormalizeEmail(req.Email)
+	if lockedFor := s.loginAttempts.LockedFor(email); lockedFor > 0 {
+		metrics.LoginFailuresTotal.WithLabelValues("locked").Inc()
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+		return utils.JsonErrorRateLimit(ctx, constants.ErrAccountLocked)
+	}
+
+	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
-		s.logAuth(ctx, nil, models.ActionLogin, map[string]interface{}{
-			"email": req.Email,
-			"status": "failed",
-			"reason": "user not found",
-		})
-		err := errors.New("username or password is wrong")
-		return utils.JsonErrorUnauthorized(ctx, err)
+		// compare against a dummy hash so unknown accounts take as long as real ones
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		return s.loginFailed(ctx, nil, email, "user not found")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		s.logAuth(ctx, &user.ID, models.ActionLogin, map[string]interface{}{
-			"email":  req.Email,
-			"status": "failed",
-			"reason": "wrong password",
-		})
-		err := errors.New("username or password is wrong")
-		return utils.JsonErrorValidation(ctx, err)
+		return s.loginFailed(ctx, &user.ID, email, "wrong password")
+	}
+
+	s.loginAttempts.Reset(email)
+
+	if user.IsClosed() {
+		return utils.JsonErrorForbidden(ctx, constants.ErrAccountClosed)
 	}
 
+	if user.IsSuspended() {
+		return utils.JsonErrorForbidden(ctx, constants.ErrAccountSuspended)
+	}
+
+	session, err := s.sessionService.Start(ctx, user.ID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_SESSION_CREATE")
+	}
 
-	token, err := s.generateToken(user.ID.String(), user.RoleID)
+	token, err := s.generateToken(user.ID.String(), user.RoleID, session.ID.String())
 	if err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_TOKEN_GENERATE")
 	}
 
 	s.logAuth(ctx, &user.ID, models.ActionLogin, map[string]interface{}{
-		"email":  user.Email,
-		"status": "success",
+		"email":      user.Email,
+		"status":     "success",
+		"session_id": session.ID.String(),
 	})
 	return utils.JsonSuccess(ctx, dto.AuthResponse{
 		Token: token,
 	})
 }
 
+func (s *AuthService) loginFailed(ctx *fiber.Ctx, userID *uuid.UUID, email string, reason string) error {
+	metrics.LoginFailuresTotal.WithLabelValues(strings.ReplaceAll(reason, " ", "_")).Inc()
+	s.logAuth(ctx, userID, models.ActionLogin, map[string]interface{}{
+		"email":  email,
+		"status": "failed",
+		"reason": reason,
+	})
+
+	if lockedFor := s.loginAttempts.RegisterFailure(email); lockedFor > 0 {
+		metrics.AccountLockoutsTotal.Inc()
+		s.logAuth(ctx, userID, models.ActionLockout, map[string]interface{}{
+			"email":           email,
+			"locked_for_secs": int(lockedFor.Seconds()),
+		})
+	}
+
+	return utils.JsonErrorUnauthorized(ctx, constants.ErrInvalidAuth)
+}
+
 func (s *AuthService) Register(ctx *fiber.Ctx, req dto.RegisterRequest) error {
 	if errors := utils.ValidateStruct(req); errors != nil {
 		return utils.JsonErrorValidationFields(ctx, errors)
@@ -121,7 +175,25 @@ func (s *AuthService) RefreshToken(ctx *fiber.Ctx, userID uuid.UUID) error {
 		return utils.JsonErrorUnauthorized(ctx, errors.New("user not found"))
 	}
 
-	token, err := s.generateToken(user.ID.String(), user.RoleID)
+	if user.IsClosed() {
+		return utils.JsonErrorForbidden(ctx, constants.ErrAccountClosed)
+	}
+
+	if user.IsSuspended() {
+		return utils.JsonErrorForbidden(ctx, constants.ErrAccountSuspended)
+	}
+
+	// tokens from before sessions have none, refreshing one starts it
+	sessionID, _ := ctx.Locals("session_id").(string)
+	if sessionID == "" {
+		session, err := s.sessionService.Start(ctx, user.ID)
+		if err != nil {
+			return utils.JsonErrorInternal(ctx, err, "E_SESSION_CREATE")
+		}
+		sessionID = session.ID.String()
+	}
+
+	token, err := s.generateToken(user.ID.String(), user.RoleID, sessionID)
 	if err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_TOKEN_GENERATE")
 	}
@@ -129,6 +201,7 @@ func (s *AuthService) RefreshToken(ctx *fiber.Ctx, userID uuid.UUID) error {
 	s.logAuth(ctx, &user.ID, models.ActionRefreshToken, map[string]interface{}{
 		"email": user.Email,
 		"status": "success",
+		"session_id": sessionID,
 	})
 
 	return utils.JsonSuccess(ctx, dto.AuthResponse{
@@ -145,33 +218,35 @@ func (s *AuthService) hashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-func (s *AuthService) generateToken(userUUID string, role models.Role) (string, error) {
+func (s *AuthService) generateToken(userUUID string, role models.Role, sessionID string) (string, error) {
 	expireHours, _ := strconv.Atoi(os.Getenv("JWT_EXPIRES"))
 	if expireHours == 0 {
 		expireHours = 24
 	}
-	expiresAt := time.Now().Add(time.Duration(expireHours) * time.Hour).Unix()
+	now := time.Now()
 
 	claims := middlewares.JwtCustomClaims{
-		Issuer: userUUID,
-		Role: role,
+		Role:      role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expiresAt,
+			Id:        uuid.NewString(),
+			Issuer:    configs.JwtKeys.Issuer,
+			Audience:  configs.JwtKeys.Audience,
+			Subject:   userUUID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Duration(expireHours) * time.Hour).Unix(),
 		},
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", err
-	}
+	return configs.JwtKeys.Sign(claims)
+}
 
-	return signedString, nil
+func (s *AuthService) Jwks(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(configs.JwtKeys.JWKS())
 }
 
 func (s *AuthService) logAuth(ctx *fiber.Ctx, userID *uuid.UUID, action models.AuditAction, details map[string]interface{}) {
-	details["ip"] = ctx.IP()
 	details["user_agent"] = string(ctx.Request().Header.UserAgent())
 
 	detailsJSON, _ := json.Marshal(details)
@@ -180,11 +255,12 @@ func (s *AuthService) logAuth(ctx *fiber.Ctx, userID *uuid.UUID, action models.A
 		EntityType: models.EntityUser,
 		Action: action,
 		Details: string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
 	}
 
 	if userID != nil {
 		log.EntityID = *userID
 	}
 	
-	go s.auditRepo.Create(log)
+	s.auditOutbox.Record(log)
 }
\ No newline at end of file
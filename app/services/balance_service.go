@@ -8,6 +8,8 @@ import (
 	"backend-path/constants"
 	"backend-path/utils"
 	"encoding/json"
+	"errors"
+	"os"
 	"strconv"
 	"time"
 
@@ -57,14 +59,31 @@ func (s *BalanceService) GetCurrent(ctx *fiber.Ctx, userID uuid.UUID) error {
 func (s *BalanceService) GetHistorical(ctx *fiber.Ctx, userID uuid.UUID) error {
 	pagination := utils.GetPagination(ctx)
 	cacheKey := s.keyBalanceHistoryCache(userID, pagination.Page, pagination.Limit)
-	cacheData := s.getBalanceHistoryCache(cacheKey)
 
-	if cacheData != nil {
-		return utils.JsonSuccess(ctx, cacheData)
+	// a custom start is not cached, cache invalidation only knows the
+	// default window
+	var since *time.Time
+	if days := balanceHistoryDays(); days > 0 {
+		start := time.Now().AddDate(0, 0, -days)
+		since = &start
+	}
+	if from := ctx.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return utils.JsonErrorValidation(ctx, errors.New("from must be an RFC3339 timestamp"))
+		}
+		since, cacheKey = &parsed, ""
+	}
+
+	if cacheKey != "" {
+		if cacheData := s.getBalanceHistoryCache(cacheKey); cacheData != nil {
+			return utils.JsonSuccess(ctx, cacheData)
+		}
 	}
 
 	logs, total, err := s.balanceRepo.GetBalanceHistory(
 		userID,
+		since,
 		pagination.Limit,
 		pagination.GetOffset(),
 	)
@@ -79,7 +98,9 @@ func (s *BalanceService) GetHistorical(ctx *fiber.Ctx, userID uuid.UUID) error {
 		total,
 	)
 
-	s.setCache(cacheKey, response)
+	if cacheKey != "" {
+		s.setCache(cacheKey, response)
+	}
 	return utils.JsonSuccess(ctx, response)
 }
 
@@ -214,4 +235,12 @@ func (s *BalanceService) setCache(key string, data interface{}) {
 	}
 
 	utils.Logger.Info("SET CACHE BALANCE LIST TO KEY " + key)
-}
\ No newline at end of file
+}
+
+// balanceHistoryDays is how far back the balance history goes unless the
+// request sets a start, configured with BALANCE_HISTORY_DAYS. Unset or 0
+// returns the full history.
+func balanceHistoryDays() int {
+	days, _ := strconv.Atoi(os.Getenv("BALANCE_HISTORY_DAYS"))
+	return days
+}
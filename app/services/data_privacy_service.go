@@ -0,0 +1,541 @@
+package services
+
+import (
+	"archive/zip"
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/mailer"
+	"backend-path/app/middlewares"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/storage"
+	"backend-path/app/transformer"
+	"backend-path/constants"
+	"backend-path/utils"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type IDataPrivacyService interface {
+	RequestExport(ctx *fiber.Ctx, userID uuid.UUID) error
+	GetExport(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error
+	DownloadExport(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error
+	EraseMe(ctx *fiber.Ctx, userID uuid.UUID, req dto.EraseAccountRequest) error
+	Erase(ctx *fiber.Ctx, userID uuid.UUID) error
+	CleanupExports() error
+}
+
+// DataPrivacyService answers data-subject requests: exports of everything we
+// store about a user and erasure of their personal data. Transactions and
+// balances are retained for regulatory reasons, only identifying data is
+// pseudonymized.
+type DataPrivacyService struct {
+	userRepo        repository.IUserRepository
+	balanceRepo     repository.IBalanceRepository
+	transactionRepo repository.ITransactionRepository
+	auditRepo       repository.IAuditLogRepository
+	auditOutbox     audit.IOutbox
+	sessionRepo     repository.ISessionRepository
+	apiKeyRepo      repository.IApiKeyRepository
+	exportRepo      repository.IDataExportRepository
+	kycRepo         repository.IKycRepository
+	userService     *UserService
+	sessionService  ISessionService
+	loginAttempts   ILoginAttemptService
+	blobStore       storage.BlobStore
+	mailer          mailer.Mailer
+}
+
+const dataExportStaleAfter = time.Hour
+
+func NewDataPrivacyService() *DataPrivacyService {
+	return &DataPrivacyService{
+		userRepo:        repository.NewUserRepository(),
+		balanceRepo:     repository.NewBalanceRepository(),
+		transactionRepo: repository.NewTransactionRepository(),
+		auditRepo:       repository.NewAuditRepository(),
+		auditOutbox:     audit.NewOutbox(),
+		sessionRepo:     repository.NewSessionRepository(),
+		apiKeyRepo:      repository.NewApiKeyRepository(),
+		exportRepo:      repository.NewDataExportRepository(),
+		kycRepo:         repository.NewKycRepository(),
+		userService:     NewUserService(),
+		sessionService:  NewSessionService(),
+		loginAttempts:   NewLoginAttemptService(),
+		blobStore:       storage.NewBlobStore(),
+		mailer:          mailer.NewMailer(),
+	}
+}
+
+func (s *DataPrivacyService) RequestExport(ctx *fiber.Ctx, userID uuid.UUID) error {
+	pending, err := s.exportRepo.FindPendingByUserID(userID)
+	if err == nil {
+		if time.Since(pending.CreatedAt) < dataExportStaleAfter {
+			return utils.JsonAccepted(ctx, transformer.DataExportTransformer(pending))
+		}
+		s.failExport(pending, errors.New("export did not finish"))
+	}
+
+	export := &models.DataExport{
+		UserID:    userID,
+		Status:    models.DataExportPending,
+		ExpiresAt: time.Now().Add(dataExportTTL()),
+	}
+
+	if err := s.exportRepo.Create(export); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_DATA_EXPORT")
+	}
+
+	s.logPrivacy(ctx, userID, models.ActionExport, map[string]interface{}{
+		"export_id": export.ID.String(),
+	})
+
+	go s.buildExport(export)
+
+	ctx.Location("/api/v1/users/me/data-export/" + export.ID.String())
+	return utils.JsonAccepted(ctx, transformer.DataExportTransformer(export))
+}
+
+func (s *DataPrivacyService) GetExport(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error {
+	export, err := s.exportRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("data export not found"))
+	}
+
+	return utils.JsonSuccess(ctx, transformer.DataExportTransformer(export))
+}
+
+func (s *DataPrivacyService) DownloadExport(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error {
+	export, err := s.exportRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("data export not found"))
+	}
+
+	if !export.IsDownloadable() {
+		return utils.JsonError(ctx, errors.New("data export is not ready or has expired"), "E_DATA_EXPORT_UNAVAILABLE")
+	}
+
+	reader, err := s.blobStore.Open(*export.BlobKey)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_DATA_EXPORT_READ")
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="data-export-`+export.ID.String()+`.zip"`)
+	return ctx.SendStream(reader, int(export.Size))
+}
+
+func (s *DataPrivacyService) EraseMe(ctx *fiber.Ctx, userID uuid.UUID, req dto.EraseAccountRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return utils.JsonErrorValidation(ctx, errors.New("password is wrong"))
+	}
+
+	return s.erase(ctx, user)
+}
+
+func (s *DataPrivacyService) Erase(ctx *fiber.Ctx, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	return s.erase(ctx, user)
+}
+
+// erase pseudonymizes the user row and scrubs personal data from audit log
+// details and sessions. The account is closed as part of the erasure, so
+// the same preconditions as closing apply.
+func (s *DataPrivacyService) erase(ctx *fiber.Ctx, user *models.User) error {
+	if user.IsErased() {
+		return utils.JsonError(ctx, errors.New("account already erased"), "E_ACCOUNT_ERASED")
+	}
+
+	originalEmail := user.Email
+	pseudonym := strings.ReplaceAll(user.ID.String(), "-", "")
+	now := time.Now()
+
+	user.Username = "erased" + pseudonym[:16]
+	user.Email = "erased+" + pseudonym + "@erased.invalid"
+	user.PasswordHash = "!"
+	user.PendingEmail = nil
+	user.EmailVerificationHash = nil
+	user.EmailVerificationExpiresAt = nil
+	user.StatusReason = nil
+	user.Status = models.UserStatusClosed
+	user.ErasedAt = &now
+	if user.ClosedAt == nil {
+		user.ClosedAt = &now
+	}
+
+	// the erase entry is written after the scrub, so it leaves out the ip
+	auditContext := audit.FromRequest(ctx)
+	auditContext.IP = ""
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"retained": []string{"transactions", "balances"},
+	})
+
+	err := s.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := lockClosable(tx, s.balanceRepo, s.transactionRepo, user.ID); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.Erase(tx, user, originalEmail); err != nil {
+			return err
+		}
+
+		return s.auditOutbox.RecordTx(tx, &models.AuditLog{
+			EntityType:   models.EntityUser,
+			EntityID:     user.ID,
+			Action:       models.ActionErase,
+			Details:      string(detailsJSON),
+			AuditContext: auditContext,
+		})
+	})
+	switch {
+	case errors.Is(err, errBalanceNotZero):
+		return utils.JsonError(ctx, errors.New("balance must be zero before erasing the account"), "E_BALANCE_NOT_ZERO")
+	case errors.Is(err, errPendingTransactions):
+		return utils.JsonError(ctx, errors.New("account has pending transactions"), "E_PENDING_TRANSACTIONS")
+	case err != nil:
+		return utils.JsonErrorInternal(ctx, err, "E_ACCOUNT_ERASE")
+	}
+
+	if err := s.sessionService.RevokeAll(user.ID); err != nil {
+		utils.Logger.Error("❌ REVOKE SESSIONS ERROR: " + err.Error())
+	}
+
+	if err := s.apiKeyRepo.RevokeAllByUserID(user.ID); err != nil {
+		utils.Logger.Error("❌ REVOKE API KEYS ERROR: " + err.Error())
+	}
+
+	s.deleteExports(user.ID)
+	s.deleteKycDocuments(&user.ID)
+	s.loginAttempts.Reset(originalEmail)
+	middlewares.InvalidateAccountStatusCache(user.ID.String())
+	s.userService.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "account erased"})
+}
+
+// deleteKycDocuments removes the documents of erased kyc submissions, of
+// one user or any. A document that cannot be deleted stays linked to its
+// submission and is retried by CleanupExports.
+func (s *DataPrivacyService) deleteKycDocuments(userID *uuid.UUID) {
+	submissions, err := s.kycRepo.FindErasedWithDocument(userID, 100)
+	if err != nil {
+		utils.Logger.Error("❌ FIND ERASED KYC DOCUMENTS ERROR: " + err.Error())
+		return
+	}
+
+	for _, submission := range submissions {
+		if err := s.blobStore.Delete(submission.DocumentBlobKey); err != nil {
+			utils.Logger.Error("❌ DELETE KYC DOCUMENT " + submission.ID.String() + " ERROR: " + err.Error())
+			continue
+		}
+
+		if err := s.kycRepo.ClearDocument(submission.ID); err != nil {
+			utils.Logger.Error("❌ DELETE KYC DOCUMENT " + submission.ID.String() + " ERROR: " + err.Error())
+		}
+	}
+}
+
+// CleanupExports removes archives past their expiry and kyc documents of
+// erased accounts that could not be deleted before.
+func (s *DataPrivacyService) CleanupExports() error {
+	exports, err := s.exportRepo.FindExpired(time.Now(), 100)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		s.deleteExport(&export)
+	}
+
+	s.deleteKycDocuments(nil)
+
+	utils.Logger.Info("✅ REMOVED " + strconv.Itoa(len(exports)) + " EXPIRED DATA EXPORTS")
+	return nil
+}
+
+func (s *DataPrivacyService) buildExport(export *models.DataExport) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.failExport(export, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	archive, err := s.assembleArchive(export.UserID)
+	if err != nil {
+		s.failExport(export, err)
+		return
+	}
+
+	blobKey := "exports/" + export.UserID.String() + "/" + export.ID.String() + ".zip"
+	if err := s.blobStore.Put(blobKey, bytes.NewReader(archive)); err != nil {
+		s.failExport(export, err)
+		return
+	}
+
+	completedAt := time.Now()
+	export.Status = models.DataExportCompleted
+	export.BlobKey = &blobKey
+	export.Size = int64(len(archive))
+	export.CompletedAt = &completedAt
+
+	if err := s.exportRepo.Update(export); err != nil {
+		utils.Logger.Error("❌ DATA EXPORT " + export.ID.String() + " ERROR: " + err.Error())
+		return
+	}
+
+	user, err := s.userRepo.FindByID(export.UserID)
+	if err != nil {
+		return
+	}
+
+	body := "Your data export is ready and can be downloaded until " +
+		export.ExpiresAt.Format(constants.TimestampFormat) + "."
+	if err := s.mailer.Send(user.Email, "Your data export is ready", body); err != nil {
+		utils.Logger.Error("❌ SEND DATA EXPORT MAIL ERROR: " + err.Error())
+	}
+}
+
+// assembleArchive collects everything stored about the user. Each dataset is
+// written as JSON, tabular datasets additionally as CSV. Counterparties of
+// transactions are referenced by id only.
+func (s *DataPrivacyService) assembleArchive(userID uuid.UUID) ([]byte, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs, err := s.auditRepo.FindByEntityID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	kycSubmissions, err := s.kycRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	if err := writeZipJSON(archive, "profile.json", transformer.UserTransformer(user)); err != nil {
+		return nil, err
+	}
+
+	if err := writeZipJSON(archive, "kyc_submissions.json", transformer.KycSubmissionListTransformer(kycSubmissions)); err != nil {
+		return nil, err
+	}
+
+	if balance, err := s.balanceRepo.FindByUserID(userID); err == nil {
+		if err := writeZipJSON(archive, "balance.json", transformer.BalanceTransformer(balance)); err != nil {
+			return nil, err
+		}
+	}
+
+	transactionRows := [][]string{{"id", "type", "status", "direction", "amount", "counterparty_id", "created_at"}}
+	transactionItems := make([]map[string]interface{}, 0, len(transactions))
+	for _, tx := range transactions {
+		direction, counterparty := "in", tx.FromUserID
+		if tx.FromUserID != nil && *tx.FromUserID == userID {
+			direction, counterparty = "out", tx.ToUserID
+		}
+
+		counterpartyID := ""
+		if counterparty != nil {
+			counterpartyID = counterparty.String()
+		}
+
+		transactionRows = append(transactionRows, []string{
+			tx.ID.String(), tx.Type.String(), tx.Status.String(), direction,
+			strconv.FormatFloat(tx.Amount, 'f', 2, 64), counterpartyID,
+			tx.CreatedAt.Format(constants.TimestampFormat),
+		})
+		transactionItems = append(transactionItems, map[string]interface{}{
+			"id":              tx.ID,
+			"type":            tx.Type.String(),
+			"status":          tx.Status.String(),
+			"direction":       direction,
+			"amount":          tx.Amount,
+			"counterparty_id": counterpartyID,
+			"created_at":      tx.CreatedAt.Format(constants.TimestampFormat),
+		})
+	}
+
+	auditRows := [][]string{{"id", "entity_type", "action", "details", "created_at"}}
+	auditItems := make([]map[string]interface{}, 0, len(auditLogs))
+	for _, log := range auditLogs {
+		details := json.RawMessage(log.Details)
+		if len(details) == 0 {
+			details = json.RawMessage("{}")
+		}
+
+		auditRows = append(auditRows, []string{
+			log.ID.String(), log.EntityType.String(), log.Action.String(), log.Details,
+			log.CreatedAt.Format(constants.TimestampFormat),
+		})
+		auditItems = append(auditItems, map[string]interface{}{
+			"id":          log.ID,
+			"entity_type": log.EntityType.String(),
+			"action":      log.Action.String(),
+			"details":     details,
+			"created_at":  log.CreatedAt.Format(constants.TimestampFormat),
+		})
+	}
+
+	sessionRows := [][]string{{"id", "device", "ip", "user_agent", "created_at", "last_activity_at", "expires_at", "revoked_at"}}
+	for _, session := range sessions {
+		revokedAt := ""
+		if session.RevokedAt != nil {
+			revokedAt = session.RevokedAt.Format(constants.TimestampFormat)
+		}
+
+		sessionRows = append(sessionRows, []string{
+			session.ID.String(), session.Device, session.IP, session.UserAgent,
+			session.CreatedAt.Format(constants.TimestampFormat),
+			session.LastActivityAt.Format(constants.TimestampFormat),
+			session.ExpiresAt.Format(constants.TimestampFormat),
+			revokedAt,
+		})
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+		rows [][]string
+	}{
+		{"transactions", transactionItems, transactionRows},
+		{"audit_logs", auditItems, auditRows},
+		{"sessions", sessions, sessionRows},
+	}
+
+	for _, file := range files {
+		if err := writeZipJSON(archive, file.name+".json", file.data); err != nil {
+			return nil, err
+		}
+
+		if err := writeZipCSV(archive, file.name+".csv", file.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *DataPrivacyService) failExport(export *models.DataExport, cause error) {
+	utils.Logger.Error("❌ DATA EXPORT " + export.ID.String() + " FAILED: " + cause.Error())
+
+	message := truncate(cause.Error(), 255)
+	export.Status = models.DataExportFailed
+	export.Error = &message
+
+	if err := s.exportRepo.Update(export); err != nil {
+		utils.Logger.Error("❌ DATA EXPORT " + export.ID.String() + " ERROR: " + err.Error())
+	}
+}
+
+func (s *DataPrivacyService) deleteExports(userID uuid.UUID) {
+	exports, err := s.exportRepo.FindByUserID(userID)
+	if err != nil {
+		utils.Logger.Error("❌ FIND DATA EXPORTS ERROR: " + err.Error())
+		return
+	}
+
+	for _, export := range exports {
+		s.deleteExport(&export)
+	}
+}
+
+func (s *DataPrivacyService) deleteExport(export *models.DataExport) {
+	if export.BlobKey != nil {
+		if err := s.blobStore.Delete(*export.BlobKey); err != nil {
+			utils.Logger.Error("❌ DELETE DATA EXPORT " + export.ID.String() + " ERROR: " + err.Error())
+			return
+		}
+	}
+
+	if err := s.exportRepo.Delete(export.ID); err != nil {
+		utils.Logger.Error("❌ DELETE DATA EXPORT " + export.ID.String() + " ERROR: " + err.Error())
+	}
+}
+
+func (s *DataPrivacyService) logPrivacy(ctx *fiber.Ctx, userID uuid.UUID, action models.AuditAction, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     userID,
+		Action:       action,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+func writeZipJSON(archive *zip.Writer, name string, data interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func writeZipCSV(archive *zip.Writer, name string, rows [][]string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func dataExportTTL() time.Duration {
+	hours, _ := strconv.Atoi(os.Getenv("DATA_EXPORT_TTL_HOURS"))
+	if hours == 0 {
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"backend-path/app/lifecycle"
+	"backend-path/app/repository"
+	"backend-path/configs"
+	"backend-path/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/storage/redis"
+)
+
+const healthCheckTimeout = time.Second
+
+type IHealthService interface {
+	Live(ctx *fiber.Ctx) error
+	Ready(ctx *fiber.Ctx) error
+}
+
+type HealthService struct {
+	redisStorage *redis.Storage
+}
+
+func NewHealthService() *HealthService {
+	return &HealthService{
+		redisStorage: configs.RedisStorage,
+	}
+}
+
+func (s *HealthService) Live(ctx *fiber.Ctx) error {
+	return utils.JsonSuccess(ctx, fiber.Map{"status": "alive"})
+}
+
+// Ready fails as soon as a shutdown starts, before the instance stops
+// taking requests, so load balancers move traffic away in time.
+func (s *HealthService) Ready(ctx *fiber.Ctx) error {
+	if !lifecycle.IsReady() {
+		return utils.JsonErrorUnavailable(ctx, errors.New("instance is not ready"), "E_NOT_READY", 5)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.UserContext(), healthCheckTimeout)
+	defer cancel()
+
+	sqlDB, err := repository.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(checkCtx)
+	}
+	if err != nil {
+		return utils.JsonErrorUnavailable(ctx, errors.New("database unavailable: "+err.Error()), "E_NOT_READY", 5)
+	}
+
+	// redis is optional, without it there is nothing to check
+	if s.redisStorage != nil {
+		if err := s.redisStorage.Conn().Ping(checkCtx).Err(); err != nil {
+			return utils.JsonErrorUnavailable(ctx, errors.New("redis unavailable: "+err.Error()), "E_NOT_READY", 5)
+		}
+	}
+
+	return utils.JsonSuccess(ctx, fiber.Map{"status": "ready"})
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/storage"
+	"backend-path/app/transformer"
+	"backend-path/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IKycService interface {
+	Submit(ctx *fiber.Ctx, userID uuid.UUID, req dto.KycSubmitRequest, document *multipart.FileHeader) error
+	GetMine(ctx *fiber.Ctx, userID uuid.UUID) error
+	GetAll(ctx *fiber.Ctx, status string) error
+	GetByID(ctx *fiber.Ctx, id uuid.UUID) error
+	Document(ctx *fiber.Ctx, id uuid.UUID) error
+	Approve(ctx *fiber.Ctx, id uuid.UUID, req dto.KycReviewRequest) error
+	Reject(ctx *fiber.Ctx, id uuid.UUID, req dto.KycRejectRequest) error
+}
+
+type KycService struct {
+	kycRepo     repository.IKycRepository
+	userRepo    repository.IUserRepository
+	auditOutbox audit.IOutbox
+	blobStore   storage.BlobStore
+}
+
+var kycDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+func NewKycService() *KycService {
+	return &KycService{
+		kycRepo:     repository.NewKycRepository(),
+		userRepo:    repository.NewUserRepository(),
+		auditOutbox: audit.NewOutbox(),
+		blobStore:   storage.NewBlobStore(),
+	}
+}
+
+// KycDocumentMaxBytes is the largest identity document accepted, configured
+// with KYC_DOCUMENT_MAX_MB.
+func KycDocumentMaxBytes() int {
+	maxMb, _ := strconv.Atoi(os.Getenv("KYC_DOCUMENT_MAX_MB"))
+	if maxMb == 0 {
+		maxMb = 5
+	}
+	return maxMb * 1024 * 1024
+}
+
+func (s *KycService) Submit(ctx *fiber.Ctx, userID uuid.UUID, req dto.KycSubmitRequest, document *multipart.FileHeader) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	requestedTier := parseKycTier(req.RequestedTier)
+	if requestedTier <= user.KycTier {
+		return utils.JsonErrorValidation(ctx, errors.New("requested tier must be above the current tier"))
+	}
+
+	if s.kycRepo.HasPending(userID) {
+		return utils.JsonError(ctx, errors.New("a kyc submission is already under review"), "E_KYC_PENDING")
+	}
+
+	dateOfBirth, _ := time.Parse(time.DateOnly, req.DateOfBirth)
+	if !dateOfBirth.Before(time.Now().AddDate(-18, 0, 0)) {
+		return utils.JsonErrorValidation(ctx, errors.New("applicant must be at least 18 years old"))
+	}
+
+	if document == nil {
+		return utils.JsonErrorValidation(ctx, errors.New("document file is required"))
+	}
+
+	if document.Size > int64(KycDocumentMaxBytes()) {
+		return utils.JsonErrorValidation(ctx, errors.New("document file is too large"))
+	}
+
+	content, contentType, err := readKycDocument(document)
+	if err != nil {
+		return utils.JsonErrorValidation(ctx, err)
+	}
+
+	submission := &models.KycSubmission{
+		ID:                  uuid.New(),
+		UserID:              userID,
+		RequestedTier:       requestedTier,
+		Status:              models.KycStatusPending,
+		FullName:            strings.TrimSpace(req.FullName),
+		DateOfBirth:         &dateOfBirth,
+		Country:             strings.ToUpper(req.Country),
+		DocumentType:        req.DocumentType,
+		DocumentNumber:      strings.ToUpper(req.DocumentNumber),
+		DocumentContentType: contentType,
+	}
+	submission.DocumentBlobKey = "kyc/" + userID.String() + "/" + submission.ID.String() + kycDocumentTypes[contentType]
+
+	if err := s.blobStore.Put(submission.DocumentBlobKey, bytes.NewReader(content)); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_DOCUMENT_STORE")
+	}
+
+	if err := s.kycRepo.Create(submission); err != nil {
+		s.blobStore.Delete(submission.DocumentBlobKey)
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_SUBMIT")
+	}
+
+	s.logKyc(ctx, submission, models.ActionKycSubmit, map[string]interface{}{
+		"user_id":        userID.String(),
+		"requested_tier": requestedTier.String(),
+	})
+
+	return utils.JsonAccepted(ctx, transformer.KycSubmissionTransformer(submission))
+}
+
+func (s *KycService) GetMine(ctx *fiber.Ctx, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	submissions, err := s.kycRepo.FindByUserID(userID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, dto.KycStatusResponse{
+		Tier:        user.KycTier.String(),
+		Policy:      transformer.KycPolicyTransformer(user.KycTier.Policy()),
+		Submissions: transformer.KycSubmissionListTransformer(submissions),
+	})
+}
+
+func (s *KycService) GetAll(ctx *fiber.Ctx, status string) error {
+	var filter models.KycStatus
+	if status != "" {
+		for candidate := models.KycStatusPending; candidate.IsValid(); candidate++ {
+			if candidate.String() == status {
+				filter = candidate
+			}
+		}
+
+		if filter == 0 {
+			return utils.JsonErrorValidation(ctx, errors.New("invalid status filter"))
+		}
+	}
+
+	pagination := utils.GetPagination(ctx)
+	submissions, total, err := s.kycRepo.FindAll(filter, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, dto.NewPaginatedResponse(
+		transformer.KycSubmissionListTransformer(submissions),
+		pagination.Page,
+		pagination.Limit,
+		total,
+	))
+}
+
+func (s *KycService) GetByID(ctx *fiber.Ctx, id uuid.UUID) error {
+	submission, err := s.kycRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("kyc submission not found"))
+	}
+
+	return utils.JsonSuccess(ctx, transformer.KycSubmissionTransformer(submission))
+}
+
+func (s *KycService) Document(ctx *fiber.Ctx, id uuid.UUID) error {
+	submission, err := s.kycRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("kyc submission not found"))
+	}
+
+	if submission.IsErased() {
+		return utils.JsonErrorNotFound(ctx, errors.New("kyc document was erased"))
+	}
+
+	reader, err := s.blobStore.Open(submission.DocumentBlobKey)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_DOCUMENT_READ")
+	}
+
+	ctx.Set(fiber.HeaderContentType, submission.DocumentContentType)
+	ctx.Set(fiber.HeaderContentDisposition, `inline; filename="`+submission.ID.String()+kycDocumentTypes[submission.DocumentContentType]+`"`)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.SendStream(reader)
+}
+
+func (s *KycService) Approve(ctx *fiber.Ctx, id uuid.UUID, req dto.KycReviewRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	return s.review(ctx, id, models.KycStatusApproved, req.Note)
+}
+
+func (s *KycService) Reject(ctx *fiber.Ctx, id uuid.UUID, req dto.KycRejectRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	return s.review(ctx, id, models.KycStatusRejected, req.Note)
+}
+
+func (s *KycService) review(ctx *fiber.Ctx, id uuid.UUID, status models.KycStatus, note string) error {
+	submission, err := s.kycRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("kyc submission not found"))
+	}
+
+	reviewerID, err := uuid.Parse(ctx.Locals("user_auth").(string))
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	if reviewerID == submission.UserID {
+		return utils.JsonErrorForbidden(ctx, errors.New("cannot review your own kyc submission"))
+	}
+
+	if !submission.IsPending() {
+		return utils.JsonError(ctx, errors.New("kyc submission was already reviewed"), "E_KYC_REVIEWED")
+	}
+
+	reviewedAt := time.Now()
+	submission.Status = status
+	submission.ReviewerID = &reviewerID
+	submission.ReviewedAt = &reviewedAt
+	if note != "" {
+		submission.ReviewNote = &note
+	}
+
+	var tier *models.KycTier
+	action := models.ActionKycReject
+	if status == models.KycStatusApproved {
+		tier = &submission.RequestedTier
+		action = models.ActionKycApprove
+	}
+
+	if err := s.kycRepo.Review(submission, tier); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.JsonError(ctx, errors.New("kyc submission was already reviewed"), "E_KYC_REVIEWED")
+		}
+		return utils.JsonErrorInternal(ctx, err, "E_KYC_REVIEW")
+	}
+
+	s.logKyc(ctx, submission, action, map[string]interface{}{
+		"user_id":        submission.UserID.String(),
+		"reviewer_id":    reviewerID.String(),
+		"requested_tier": submission.RequestedTier.String(),
+		"note":           note,
+	})
+
+	if tier != nil && submission.User != nil {
+		submission.User.KycTier = *tier
+		NewUserService().refreshUserCache(submission.User)
+	}
+
+	return utils.JsonSuccess(ctx, transformer.KycSubmissionTransformer(submission))
+}
+
+func (s *KycService) logKyc(ctx *fiber.Ctx, submission *models.KycSubmission, action models.AuditAction, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityKycSubmission,
+		EntityID:     submission.ID,
+		Action:       action,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+// readKycDocument loads the upload and determines its type from the content,
+// the client supplied content type is not trusted.
+func readKycDocument(document *multipart.FileHeader) ([]byte, string, error) {
+	file, err := document.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, int64(KycDocumentMaxBytes())+1))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(content) > KycDocumentMaxBytes() {
+		return nil, "", errors.New("document file is too large")
+	}
+
+	contentType := http.DetectContentType(content)
+	if _, ok := kycDocumentTypes[contentType]; !ok {
+		return nil, "", errors.New("document must be a jpeg, png or pdf file")
+	}
+
+	return content, contentType, nil
+}
+
+func parseKycTier(name string) models.KycTier {
+	for tier := models.KycTierBasic; tier.IsValid(); tier++ {
+		if tier.String() == name {
+			return tier
+		}
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"backend-path/configs"
+	"backend-path/constants"
+	"backend-path/utils"
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ILoginAttemptService interface {
+	LockedFor(email string) time.Duration
+	RegisterFailure(email string) time.Duration
+	Reset(email string)
+}
+
+// LoginAttemptService keeps failed login counters per account (keyed by the
+// normalized email, so unknown accounts are throttled exactly like real ones).
+// Once the threshold is reached the account is locked, and every further
+// lockout inside the lockout window doubles the lock duration.
+type LoginAttemptService struct {
+	maxAttempts int
+	window      time.Duration
+	baseLockout time.Duration
+	maxLockout  time.Duration
+}
+
+func NewLoginAttemptService() *LoginAttemptService {
+	maxAttempts, _ := strconv.Atoi(os.Getenv("LOGIN_MAX_ATTEMPTS"))
+	if maxAttempts == 0 {
+		maxAttempts = 5
+	}
+
+	windowMinutes, _ := strconv.Atoi(os.Getenv("LOGIN_ATTEMPT_WINDOW_MINUTES"))
+	if windowMinutes == 0 {
+		windowMinutes = 15
+	}
+
+	lockoutMinutes, _ := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES"))
+	if lockoutMinutes == 0 {
+		lockoutMinutes = 5
+	}
+
+	maxLockoutMinutes, _ := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MAX_MINUTES"))
+	if maxLockoutMinutes == 0 {
+		maxLockoutMinutes = 24 * 60
+	}
+
+	return &LoginAttemptService{
+		maxAttempts: maxAttempts,
+		window:      time.Duration(windowMinutes) * time.Minute,
+		baseLockout: time.Duration(lockoutMinutes) * time.Minute,
+		maxLockout:  time.Duration(maxLockoutMinutes) * time.Minute,
+	}
+}
+
+// LockedFor returns the remaining lock duration, or zero when the account
+// may attempt to log in.
+func (s *LoginAttemptService) LockedFor(email string) time.Duration {
+	if configs.RedisStorage == nil {
+		return 0
+	}
+
+	ttl, err := configs.RedisStorage.Conn().TTL(context.Background(), s.keyLock(email)).Result()
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+
+	return ttl
+}
+
+// RegisterFailure counts a failed attempt and locks the account when the
+// threshold is reached. It returns the lock duration that was applied, or
+// zero when the account is still allowed to retry.
+func (s *LoginAttemptService) RegisterFailure(email string) time.Duration {
+	if configs.RedisStorage == nil {
+		return 0
+	}
+
+	ctx := context.Background()
+	conn := configs.RedisStorage.Conn()
+
+	attemptsKey := s.keyAttempts(email)
+	attempts, err := conn.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		utils.Logger.Error("❌ LOGIN ATTEMPT COUNTER ERROR: " + err.Error())
+		return 0
+	}
+
+	if attempts == 1 {
+		conn.Expire(ctx, attemptsKey, s.window)
+	}
+
+	if attempts < int64(s.maxAttempts) {
+		return 0
+	}
+
+	lockoutsKey := s.keyLockouts(email)
+	lockouts, err := conn.Incr(ctx, lockoutsKey).Result()
+	if err != nil {
+		lockouts = 1
+	}
+	conn.Expire(ctx, lockoutsKey, s.maxLockout)
+
+	lockFor := s.lockDuration(lockouts)
+	conn.Set(ctx, s.keyLock(email), strconv.FormatInt(lockouts, 10), lockFor)
+	conn.Del(ctx, attemptsKey)
+
+	return lockFor
+}
+
+// Reset clears the failed attempt counters and any active lock.
+func (s *LoginAttemptService) Reset(email string) {
+	if configs.RedisStorage == nil {
+		return
+	}
+
+	configs.RedisStorage.Conn().Del(
+		context.Background(),
+		s.keyAttempts(email),
+		s.keyLock(email),
+		s.keyLockouts(email),
+	)
+}
+
+func (s *LoginAttemptService) lockDuration(lockouts int64) time.Duration {
+	multiplier := math.Pow(2, float64(lockouts-1))
+	lockFor := time.Duration(float64(s.baseLockout) * multiplier)
+	if lockFor <= 0 || lockFor > s.maxLockout {
+		return s.maxLockout
+	}
+
+	return lockFor
+}
+
+func (s *LoginAttemptService) keyAttempts(email string) string {
+	return constants.CacheLoginAttempts + "_" + NormalizeEmail(email)
+}
+
+func (s *LoginAttemptService) keyLock(email string) string {
+	return constants.CacheLoginLock + "_" + NormalizeEmail(email)
+}
+
+func (s *LoginAttemptService) keyLockouts(email string) string {
+	return constants.CacheLoginLockouts + "_" + NormalizeEmail(email)
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/middlewares"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/transformer"
+	"backend-path/configs"
+	"backend-path/utils"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/storage/redis"
+)
+
+type IRoleService interface {
+	GetAll(ctx *fiber.Ctx) error
+	GetByID(ctx *fiber.Ctx, id models.Role) error
+	Create(ctx *fiber.Ctx, req dto.CreateRoleRequest) error
+	Update(ctx *fiber.Ctx, id models.Role, req dto.UpdateRoleRequest) error
+	Delete(ctx *fiber.Ctx, id models.Role) error
+	GetPermissions(ctx *fiber.Ctx) error
+}
+
+type RoleService struct {
+	roleRepo       repository.IRoleRepository
+	permissionRepo repository.IPermissionRepository
+	auditOutbox    audit.IOutbox
+	redisStorage   *redis.Storage
+}
+
+func NewRoleService() *RoleService {
+	return &RoleService{
+		roleRepo:       repository.NewRoleRepository(),
+		permissionRepo: repository.NewPermissionRepository(),
+		auditOutbox:    audit.NewOutbox(),
+		redisStorage:   configs.RedisStorage,
+	}
+}
+
+func (s *RoleService) GetAll(ctx *fiber.Ctx) error {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_ROLE_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, transformer.RoleListTransformer(roles))
+}
+
+func (s *RoleService) GetByID(ctx *fiber.Ctx, id models.Role) error {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("role not found"))
+	}
+
+	return utils.JsonSuccess(ctx, transformer.RoleTransformer(role))
+}
+
+func (s *RoleService) Create(ctx *fiber.Ctx, req dto.CreateRoleRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if _, err := s.roleRepo.FindByName(name); err == nil {
+		return utils.JsonErrorValidation(ctx, errors.New("role name already exist"))
+	}
+
+	permissions, err := s.resolvePermissions(req.Permissions)
+	if err != nil {
+		return utils.JsonErrorValidation(ctx, err)
+	}
+
+	role := &models.RoleDefinition{
+		Name:        name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+
+	if err := s.roleRepo.Create(role); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_ROLE_CREATE")
+	}
+
+	s.logRole(ctx, role, models.ActionCreate)
+
+	return utils.JsonSuccess(ctx, transformer.RoleTransformer(role))
+}
+
+func (s *RoleService) Update(ctx *fiber.Ctx, id models.Role, req dto.UpdateRoleRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("role not found"))
+	}
+
+	if req.Name != "" {
+		name := strings.ToLower(strings.TrimSpace(req.Name))
+		if name != role.Name {
+			if id.IsSystem() {
+				return utils.JsonErrorForbidden(ctx, errors.New("system roles cannot be renamed"))
+			}
+			if _, err := s.roleRepo.FindByName(name); err == nil {
+				return utils.JsonErrorValidation(ctx, errors.New("role name already exist"))
+			}
+			role.Name = name
+		}
+	}
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	if req.Permissions != nil {
+		permissions, err := s.resolvePermissions(*req.Permissions)
+		if err != nil {
+			return utils.JsonErrorValidation(ctx, err)
+		}
+
+		if id == models.RoleAdmin && !containsPermission(permissions, models.PermRolesManage) {
+			return utils.JsonErrorForbidden(ctx, errors.New("admin role must keep "+models.PermRolesManage))
+		}
+		role.Permissions = permissions
+	}
+
+	if err := s.roleRepo.Update(role); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_ROLE_UPDATE")
+	}
+
+	s.invalidatePermissionsCache(id)
+	s.logRole(ctx, role, models.ActionUpdate)
+
+	return utils.JsonSuccess(ctx, transformer.RoleTransformer(role))
+}
+
+func (s *RoleService) Delete(ctx *fiber.Ctx, id models.Role) error {
+	if id.IsSystem() {
+		return utils.JsonErrorForbidden(ctx, errors.New("system roles cannot be deleted"))
+	}
+
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("role not found"))
+	}
+
+	assigned, err := s.roleRepo.CountUsers(id)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_ROLE_DELETE")
+	}
+
+	if assigned > 0 {
+		return utils.JsonError(ctx, errors.New("role is still assigned to users"), "E_ROLE_IN_USE")
+	}
+
+	if err := s.roleRepo.Delete(id); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_ROLE_DELETE")
+	}
+
+	s.invalidatePermissionsCache(id)
+	s.logRole(ctx, role, models.ActionDelete)
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "role deleted"})
+}
+
+func (s *RoleService) GetPermissions(ctx *fiber.Ctx) error {
+	permissions, err := s.permissionRepo.FindAll()
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_PERMISSION_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, transformer.PermissionListTransformer(permissions))
+}
+
+func (s *RoleService) resolvePermissions(names []string) ([]models.Permission, error) {
+	permissions, err := s.permissionRepo.FindByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		found[p.Name] = true
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, errors.New("unknown permission " + name)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *RoleService) invalidatePermissionsCache(id models.Role) {
+	if s.redisStorage == nil {
+		return
+	}
+
+	s.redisStorage.Delete(middlewares.KeyRolePermissionsCache(id))
+	utils.Logger.Info("INVALIDATE ROLE PERMISSIONS CACHE FOR ROLE " + id.String())
+}
+
+func (s *RoleService) logRole(ctx *fiber.Ctx, role *models.RoleDefinition, action models.AuditAction) {
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"role_id":     uint(role.ID),
+		"name":        role.Name,
+		"permissions": role.PermissionNames(),
+	})
+
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityRole,
+		EntityID:     role.ID.EntityID(),
+		Action:       action,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+func containsPermission(permissions []models.Permission, name string) bool {
+	for _, p := range permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
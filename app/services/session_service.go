@@ -0,0 +1,161 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/middlewares"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/transformer"
+	"backend-path/utils"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const DeviceHeader = "X-Device-Name"
+
+type ISessionService interface {
+	Start(ctx *fiber.Ctx, userID uuid.UUID) (*models.Session, error)
+	RevokeAll(userID uuid.UUID) error
+	List(ctx *fiber.Ctx, userID uuid.UUID) error
+	Revoke(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error
+}
+
+type SessionService struct {
+	sessionRepo repository.ISessionRepository
+	auditOutbox audit.IOutbox
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repository.NewSessionRepository(),
+		auditOutbox: audit.NewOutbox(),
+	}
+}
+
+func (s *SessionService) Start(ctx *fiber.Ctx, userID uuid.UUID) (*models.Session, error) {
+	maxAgeDays, _ := strconv.Atoi(os.Getenv("SESSION_MAX_AGE_DAYS"))
+	if maxAgeDays == 0 {
+		maxAgeDays = 30
+	}
+
+	userAgent := string(ctx.Request().Header.UserAgent())
+	now := time.Now()
+
+	session := &models.Session{
+		UserID:         userID,
+		Device:         truncate(deviceName(ctx.Get(DeviceHeader), userAgent), 100),
+		IP:             truncate(ctx.IP(), 45),
+		UserAgent:      truncate(userAgent, 255),
+		LastActivityAt: now,
+		ExpiresAt:      now.AddDate(0, 0, maxAgeDays),
+	}
+
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *SessionService) RevokeAll(userID uuid.UUID) error {
+	ids, err := s.sessionRepo.RevokeAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	middlewares.InvalidateSessionCache(ids...)
+	return nil
+}
+
+func (s *SessionService) List(ctx *fiber.Ctx, userID uuid.UUID) error {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_SESSION_LIST")
+	}
+
+	currentID, _ := ctx.Locals("session_id").(string)
+	return utils.JsonSuccess(ctx, transformer.SessionListTransformer(sessions, currentID))
+}
+
+func (s *SessionService) Revoke(ctx *fiber.Ctx, userID uuid.UUID, id uuid.UUID) error {
+	session, err := s.sessionRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("session not found"))
+	}
+
+	if !session.IsActive() {
+		return utils.JsonError(ctx, errors.New("session already ended"), "E_SESSION_ENDED")
+	}
+
+	if err := s.sessionRepo.Revoke(session.ID); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_SESSION_REVOKE")
+	}
+
+	middlewares.InvalidateSessionCache(session.ID)
+
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"session_id": session.ID.String(),
+		"device":     session.Device,
+		"status":     "revoked",
+	})
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     userID,
+		Action:       models.ActionLogout,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "session revoked"})
+}
+
+func deviceName(header string, userAgent string) string {
+	if header = strings.TrimSpace(header); header != "" {
+		return header
+	}
+
+	ua := strings.ToLower(userAgent)
+	platforms := []struct {
+		token string
+		name  string
+	}{
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"linux", "Linux"},
+		{"curl", "curl"},
+		{"postman", "Postman"},
+	}
+
+	for _, p := range platforms {
+		if strings.Contains(ua, p.token) {
+			return p.name
+		}
+	}
+
+	return "Unknown"
+}
+
+// truncate cuts s to at most max bytes without splitting a character.
+// Invalid UTF-8 is dropped first, postgres rejects it in text columns.
+func truncate(s string, max int) string {
+	s = strings.ToValidUTF8(s, "")
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
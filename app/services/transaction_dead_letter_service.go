@@ -0,0 +1,245 @@
+package services
+
+import (
+	"backend-path/app/audit"
+	"backend-path/app/dto"
+	"backend-path/app/metrics"
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"backend-path/app/transformer"
+	"backend-path/constants"
+	"backend-path/utils"
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ITransactionDeadLetterService interface {
+	GetAll(ctx *fiber.Ctx, status string) error
+	GetByID(ctx *fiber.Ctx, id uuid.UUID) error
+	Replay(ctx *fiber.Ctx, id uuid.UUID) error
+	Discard(ctx *fiber.Ctx, id uuid.UUID) error
+}
+
+type TransactionDeadLetterService struct {
+	deadLetterRepo     repository.ITransactionDeadLetterRepository
+	userRepo           repository.IUserRepository
+	auditOutbox        audit.IOutbox
+	transactionService *TransactionService
+}
+
+var (
+	errDeadLetterResolved      = errors.New("dead letter was already replayed or discarded")
+	errDeadLetterNotReplayable = errors.New("only asynchronous transactions can be replayed")
+)
+
+func NewTransactionDeadLetterService() *TransactionDeadLetterService {
+	return &TransactionDeadLetterService{
+		deadLetterRepo:     repository.NewTransactionDeadLetterRepository(),
+		userRepo:           repository.NewUserRepository(),
+		auditOutbox:        audit.NewOutbox(),
+		transactionService: NewTransactionService(),
+	}
+}
+
+// UpdateDeadLetterBacklog refreshes transaction_dead_letter_backlog from the
+// database, so every instance reports the same backlog.
+func UpdateDeadLetterBacklog() error {
+	total, err := repository.NewTransactionDeadLetterRepository().CountPending()
+	if err != nil {
+		return err
+	}
+
+	metrics.TransactionDeadLetterBacklog.Set(float64(total))
+	return nil
+}
+
+func (s *TransactionDeadLetterService) GetAll(ctx *fiber.Ctx, status string) error {
+	var filter models.DeadLetterStatus
+	if status != "" {
+		for candidate := models.DeadLetterPending; candidate.IsValid(); candidate++ {
+			if candidate.String() == status {
+				filter = candidate
+			}
+		}
+
+		if filter == 0 {
+			return utils.JsonErrorValidation(ctx, errors.New("invalid status filter"))
+		}
+	}
+
+	pagination := utils.GetPagination(ctx)
+	deadLetters, total, err := s.deadLetterRepo.FindAll(filter, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_DEAD_LETTER_LIST")
+	}
+
+	return utils.JsonSuccess(ctx, dto.NewPaginatedResponse(
+		transformer.TransactionDeadLetterListTransformer(deadLetters),
+		pagination.Page,
+		pagination.Limit,
+		total,
+	))
+}
+
+func (s *TransactionDeadLetterService) GetByID(ctx *fiber.Ctx, id uuid.UUID) error {
+	deadLetter, err := s.deadLetterRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("dead letter not found"))
+	}
+
+	return utils.JsonSuccess(ctx, transformer.TransactionDeadLetterTransformer(deadLetter))
+}
+
+// Replay queues the original transaction again under its own id, on
+// behalf of the admin and under the same account checks as a regular
+// submission. Only a transaction that is still failed can be replayed, and
+// processing skips it once it is final, so a replay never moves money twice.
+func (s *TransactionDeadLetterService) Replay(ctx *fiber.Ctx, id uuid.UUID) error {
+	adminID, err := uuid.Parse(ctx.Locals("user_auth").(string))
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	deadLetter, err := s.deadLetterRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("dead letter not found"))
+	}
+
+	if !deadLetter.IsPending() {
+		return utils.JsonError(ctx, errDeadLetterResolved, "E_DEAD_LETTER_RESOLVED")
+	}
+
+	// the caller of a synchronous submission got an error back and may have
+	// retried on its own
+	if !deadLetter.Async {
+		return utils.JsonError(ctx, errDeadLetterNotReplayable, "E_DEAD_LETTER_NOT_REPLAYABLE")
+	}
+
+	if deadLetter.FromUserID != nil {
+		if err := s.transactionService.checkSender(ctx.UserContext(), *deadLetter.FromUserID, deadLetter.Type, deadLetter.Amount); err != nil {
+			return utils.JsonErrorForbidden(ctx, err)
+		}
+	}
+
+	if deadLetter.ToUserID != nil {
+		recipient, err := s.userRepo.FindByID(*deadLetter.ToUserID)
+		if err != nil {
+			return utils.JsonErrorNotFound(ctx, errors.New("recipient not found"))
+		}
+
+		if !recipient.CanReceive(s.transactionService.freezeBlocksAll) {
+			return utils.JsonError(ctx, constants.ErrRecipientRestricted, "E_RECIPIENT_RESTRICTED")
+		}
+	}
+
+	var transaction *models.Transaction
+	deadLetter, err = s.resolve(ctx, id, adminID, func(tx *gorm.DB, deadLetter *models.TransactionDeadLetter) error {
+		requeued, err := s.transactionService.requeueFailed(tx, deadLetter.JobID, audit.FromRequest(ctx))
+		if err != nil {
+			return err
+		}
+
+		transaction = requeued
+		deadLetter.Status = models.DeadLetterReplayed
+		deadLetter.ReplayedTransactionID = &transaction.ID
+		return nil
+	})
+	if err != nil {
+		return s.resolveError(ctx, err, "E_DEAD_LETTER_REPLAY")
+	}
+
+	s.transactionService.invalidateCachesAfterTransaction(ctx.UserContext(), transaction)
+	s.transactionService.dispatcher.Wake()
+
+	ctx.Location("/api/v1/transactions/" + transaction.ID.String())
+	return utils.JsonAccepted(ctx, transformer.TransactionDeadLetterTransformer(deadLetter))
+}
+
+func (s *TransactionDeadLetterService) Discard(ctx *fiber.Ctx, id uuid.UUID) error {
+	adminID, err := uuid.Parse(ctx.Locals("user_auth").(string))
+	if err != nil {
+		return utils.JsonErrorUnauthorized(ctx, errors.New("invalid user id"))
+	}
+
+	deadLetter, err := s.resolve(ctx, id, adminID, func(tx *gorm.DB, deadLetter *models.TransactionDeadLetter) error {
+		deadLetter.Status = models.DeadLetterDiscarded
+		return nil
+	})
+	if err != nil {
+		return s.resolveError(ctx, err, "E_DEAD_LETTER_DISCARD")
+	}
+
+	return utils.JsonSuccess(ctx, transformer.TransactionDeadLetterTransformer(deadLetter))
+}
+
+// resolve applies a decision to a pending dead letter under its row lock, so
+// two admins cannot both replay the same job, and audits it.
+func (s *TransactionDeadLetterService) resolve(ctx *fiber.Ctx, id uuid.UUID, adminID uuid.UUID, decide func(tx *gorm.DB, deadLetter *models.TransactionDeadLetter) error) (*models.TransactionDeadLetter, error) {
+	var resolved *models.TransactionDeadLetter
+
+	err := s.deadLetterRepo.GetDB().WithContext(ctx.UserContext()).Transaction(func(tx *gorm.DB) error {
+		deadLetter, err := s.deadLetterRepo.FindByIDForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !deadLetter.IsPending() {
+			return errDeadLetterResolved
+		}
+
+		if err := decide(tx, deadLetter); err != nil {
+			return err
+		}
+
+		deadLetter.ResolvedBy = &adminID
+		if err := s.deadLetterRepo.Resolve(tx, deadLetter); err != nil {
+			return err
+		}
+
+		resolved = deadLetter
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"dead_letter_id": resolved.ID.String(),
+		"resolution":     resolved.Status.String(),
+		"error":          resolved.Error,
+	}
+	if resolved.ReplayedTransactionID != nil {
+		details["replayed_transaction_id"] = resolved.ReplayedTransactionID.String()
+	}
+
+	detailsJSON, _ := json.Marshal(details)
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityTransaction,
+		EntityID:     resolved.JobID,
+		Action:       models.ActionUpdate,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+
+	if err := UpdateDeadLetterBacklog(); err != nil {
+		utils.Logger.Error("❌ DEAD LETTER BACKLOG ERROR: " + err.Error())
+	}
+
+	return resolved, nil
+}
+
+func (s *TransactionDeadLetterService) resolveError(ctx *fiber.Ctx, err error, code string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.JsonErrorNotFound(ctx, errors.New("dead letter not found"))
+	case errors.Is(err, errDeadLetterResolved):
+		return utils.JsonError(ctx, err, "E_DEAD_LETTER_RESOLVED")
+	case errors.Is(err, errTransactionNotFailed):
+		return utils.JsonError(ctx, err, "E_DEAD_LETTER_NOT_REPLAYABLE")
+	}
+	return utils.JsonErrorInternal(ctx, err, code)
+}
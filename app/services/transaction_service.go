@@ -1,6 +1,7 @@
 package services
 
 import (
+	"backend-path/app/audit"
 	"backend-path/app/dto"
 	"backend-path/app/metrics"
 	"backend-path/app/models"
@@ -10,9 +11,18 @@ import (
 	"backend-path/configs"
 	"backend-path/constants"
 	"backend-path/utils"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"math/rand/v2"
+	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,82 +38,278 @@ type ITransactionService interface {
 	Transfer(ctx *fiber.Ctx, req dto.TransferRequest, fromUserID uuid.UUID) error
 	GetByID(ctx *fiber.Ctx, id uuid.UUID, userID uuid.UUID) error
 	GetHistory(ctx *fiber.Ctx, userID uuid.UUID) error
-	GetStats(ctx *fiber.Ctx) error
+	GetStats(ctx *fiber.Ctx, req dto.TransactionStatsRequest) error
 }
 
 type TransactionService struct {
-	transactionRepo repository.ITransactionRepository
-	balanceRepo     repository.IBalanceRepository
-	auditRepo       repository.IAuditLogRepository
-	workerPool      *workers.TransactionWorkerPool
-	redisStorage    *redis.Storage
+	transactionRepo  repository.ITransactionRepository
+	balanceRepo      repository.IBalanceRepository
+	auditRepo        repository.IAuditLogRepository
+	userRepo         repository.IUserRepository
+	deadLetterRepo   repository.ITransactionDeadLetterRepository
+	statsRepo        repository.ITransactionStatsRepository
+	workerPool       *workers.TransactionWorkerPool
+	jobQueue         workers.JobQueue
+	dispatcher       *workers.JobDispatcher
+	redisStorage     *redis.Storage
+	freezeBlocksAll  bool
+	queueWait        time.Duration
+	processTimeout   time.Duration
+	retryAttempts    int
+	retryBaseDelay   time.Duration
+	maxJobDeliveries int
+	drainTimeout     time.Duration
 }
 
+const (
+	// transactionRetryAfterSeconds is sent with E_QUEUE_FULL and
+	// E_SHUTTING_DOWN responses.
+	transactionRetryAfterSeconds = 1
+	transactionCacheTimeout      = 500 * time.Millisecond
+	transactionStatusReadTimeout = time.Second
+	transactionCallbackTimeout   = 5 * time.Second
+	transactionCallbackAttempts  = 3
+	transactionRetryMaxDelay     = time.Second
+	transactionStatsMaxHours     = 31 * 24
+	transactionStatsMaxDays      = 366
+)
+
 var transactionServiceInstance *TransactionService
 
+// callbackClient only connects to public addresses, callback urls are
+// supplied by users.
+var callbackClient = utils.NewPublicHTTPClient(transactionCallbackTimeout)
+
+// errJobDeliveriesExhausted stops a job that keeps being redelivered, most
+// likely because processing it brings the instance down.
+var errJobDeliveriesExhausted = errors.New("job exceeded its delivery attempts")
+
+var errTransactionNotFailed = errors.New("transaction is not failed, it cannot be replayed")
+
 func NewTransactionService() *TransactionService {
 	if transactionServiceInstance == nil {
 		svc := &TransactionService{
 			transactionRepo: repository.NewTransactionRepository(),
 			balanceRepo: repository.NewBalanceRepository(),
 			auditRepo: repository.NewAuditRepository(),
+			userRepo: repository.NewUserRepository(),
+			deadLetterRepo: repository.NewTransactionDeadLetterRepository(),
+			statsRepo: repository.NewTransactionStatsRepository(),
 			redisStorage: configs.RedisStorage,
+			freezeBlocksAll: os.Getenv("FROZEN_ACCOUNT_POLICY") == "block_all",
+		}
+
+		workerCount, _ := strconv.Atoi(os.Getenv("TX_WORKER_COUNT"))
+		if workerCount == 0 {
+			workerCount = 5
+		}
+
+		queueSize, _ := strconv.Atoi(os.Getenv("TX_QUEUE_SIZE"))
+		if queueSize == 0 {
+			queueSize = 100
+		}
+
+		queueWaitMs, _ := strconv.Atoi(os.Getenv("TX_QUEUE_WAIT_MS"))
+		if queueWaitMs == 0 {
+			queueWaitMs = 100
 		}
+		svc.queueWait = time.Duration(queueWaitMs) * time.Millisecond
 
-		svc.workerPool = workers.NewTransactionWorkerPool(5, 100, svc.processTransaction)
+		timeoutMs, _ := strconv.Atoi(os.Getenv("TX_TIMEOUT_MS"))
+		if timeoutMs == 0 {
+			timeoutMs = 10000
+		}
+		svc.processTimeout = time.Duration(timeoutMs) * time.Millisecond
+
+		svc.retryAttempts, _ = strconv.Atoi(os.Getenv("TX_RETRY_ATTEMPTS"))
+		if svc.retryAttempts == 0 {
+			svc.retryAttempts = 3
+		}
+
+		retryBaseMs, _ := strconv.Atoi(os.Getenv("TX_RETRY_BASE_MS"))
+		if retryBaseMs == 0 {
+			retryBaseMs = 20
+		}
+		svc.retryBaseDelay = time.Duration(retryBaseMs) * time.Millisecond
+
+		drainSeconds, _ := strconv.Atoi(os.Getenv("TX_SHUTDOWN_TIMEOUT_SECONDS"))
+		if drainSeconds == 0 {
+			drainSeconds = 15
+		}
+		svc.drainTimeout = time.Duration(drainSeconds) * time.Second
+
+		svc.workerPool = workers.NewTransactionWorkerPool(workerCount, queueSize, svc.processTransaction)
 		svc.workerPool.Start()
 
+		svc.maxJobDeliveries, _ = strconv.Atoi(os.Getenv("TX_JOB_MAX_ATTEMPTS"))
+		if svc.maxJobDeliveries == 0 {
+			svc.maxJobDeliveries = 5
+		}
+
+		jobBatch, _ := strconv.Atoi(os.Getenv("TX_JOB_BATCH"))
+		if jobBatch == 0 {
+			jobBatch = 50
+		}
+
+		svc.jobQueue = workers.NewPostgresJobQueue()
+		svc.dispatcher = workers.NewJobDispatcher(svc.jobQueue, svc.workerPool, jobBatch)
+		svc.dispatcher.Start()
+
 		transactionServiceInstance = svc
 	}
 
 	return transactionServiceInstance
 }
 
+// StopTransactionWorkers stops taking jobs from the durable queue and from
+// requests, and gives the worker pool TX_SHUTDOWN_TIMEOUT_SECONDS to finish
+// what it holds. Jobs left after that are cancelled: asynchronous ones go
+// back to the durable queue for the next instance, synchronous callers get
+// E_TX_CANCELLED. Does nothing if the service was never started.
+func StopTransactionWorkers() {
+	if transactionServiceInstance == nil {
+		return
+	}
+
+	transactionServiceInstance.dispatcher.Stop()
+	transactionServiceInstance.workerPool.Stop(transactionServiceInstance.drainTimeout)
+	transactionServiceInstance.dispatcher.Wait()
+}
+
 func (s *TransactionService) processTransaction(job workers.TransactionJob) workers.TransactionResult {
-	db := s.transactionRepo.GetDB()
+	ctx, cancel := context.WithTimeout(job.Ctx, s.processTimeout)
+	defer cancel()
+
 	var resultTx *models.Transaction
+	processed := false
 
-	err := db.Transaction(func(tx *gorm.DB) error {
-		transaction := &models.Transaction{
-			ID: job.ID,
-			FromUserID: job.FromUserID,
-			ToUserID: job.ToUserID,
-			Amount: job.Amount,
-			Type: job.Type,
-			Status: models.TxStatusPending,
-		}
+	err := ctx.Err()
+	if err == nil && job.Persisted && job.Attempts > s.maxJobDeliveries {
+		err = errJobDeliveriesExhausted
+	}
 
-		if err := s.transactionRepo.Create(tx, transaction); err != nil {
-			return err
-		}
+	attempts := 0
+	for err == nil {
+		attempts++
+		resultTx, processed = nil, false
+		err = s.transactionRepo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			transaction := &models.Transaction{
+				ID: job.ID,
+				FromUserID: job.FromUserID,
+				ToUserID: job.ToUserID,
+				Amount: job.Amount,
+				Type: job.Type,
+				Status: models.TxStatusPending,
+			}
 
-		var processErr error
-		switch job.Type {
-		case models.TxTypeDeposit:
-			processErr = s.processDeposit(tx, transaction)
-		case models.TxTypeWithdraw:
-			processErr = s.processWithdraw(tx, transaction)
-		case models.TxTypeTransfer:
-			processErr = s.processTransfer(tx, transaction)
-		}
+			if job.Persisted {
+				stored, err := s.transactionRepo.FindByIDForUpdate(tx, job.ID)
+				if err != nil {
+					return err
+				}
+				transaction = stored
+			} else if err := s.transactionRepo.Create(tx, transaction); err != nil {
+				return err
+			}
 
-		if processErr != nil {
-			transaction.Fail()
-			s.transactionRepo.Update(tx, transaction)
-			return processErr
+			// already handled, an asynchronous job is never applied twice
+			if transaction.IsFinal() {
+				resultTx = transaction
+				return nil
+			}
+			processed = true
+
+			var processErr error
+			switch job.Type {
+			case models.TxTypeDeposit:
+				processErr = s.processDeposit(tx, transaction, job.Audit)
+			case models.TxTypeWithdraw:
+				processErr = s.processWithdraw(tx, transaction, job.Audit)
+			case models.TxTypeTransfer:
+				processErr = s.processTransfer(tx, transaction, job.Audit)
+			}
+
+			if processErr != nil {
+				transaction.Fail()
+				s.transactionRepo.Update(tx, transaction)
+				return processErr
+			}
+
+			transaction.Complete()
+			if err := s.transactionRepo.Update(tx, transaction); err != nil {
+				return err
+			}
+
+			if err := s.statsRepo.Record(tx, transaction.ID, transaction.CreatedAt, transaction.Type, transaction.Status, transaction.Amount); err != nil {
+				return err
+			}
+
+			resultTx = transaction
+			return nil
+		})
+
+		cause := repository.RetryableCause(err)
+		if cause == "" || attempts == s.retryAttempts {
+			break
 		}
 
-		transaction.Complete()
-		if err := s.transactionRepo.Update(tx, transaction); err != nil {
-			return err
+		metrics.TransactionRetriesTotal.WithLabelValues(cause).Inc()
+		utils.Logger.Info("RETRY TRANSACTION " + job.ID.String() + " AFTER " + cause + ", ATTEMPT " + strconv.Itoa(attempts))
+
+		select {
+		case <-time.After(s.retryDelay(attempts)):
+			err = nil
+		case <-ctx.Done():
+			err = ctx.Err()
 		}
+	}
 
-		resultTx = transaction
-		return nil
-	})
+	if resultTx != nil && !processed {
+		return workers.TransactionResult{Transaction: resultTx}
+	}
+
+	// committed or failed, either way this must happen even if the caller
+	// gave up meanwhile
+	afterCtx, cancelAfter := context.WithTimeout(context.WithoutCancel(job.Ctx), transactionCacheTimeout)
+	defer cancelAfter()
 
 	if resultTx != nil && err == nil {
-		s.invalidateCachesAfterTransaction(resultTx)
+		s.invalidateCachesAfterTransaction(afterCtx, resultTx)
+	}
+
+	// cut off by a shutdown, the job goes back to the durable queue
+	if job.Persisted && errors.Is(err, context.Canceled) {
+		return workers.TransactionResult{Error: err}
+	}
+
+	// a synchronous caller that timed out or went away already got an error
+	// back and may retry on its own, replaying it could apply it twice
+	abandoned := !job.Persisted && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+
+	if err != nil && job.Persisted {
+		if markErr := s.markFailed(afterCtx, job, err); markErr != nil {
+			utils.Logger.Error("❌ MARK TRANSACTION " + job.ID.String() + " FAILED ERROR: " + markErr.Error())
+		}
+		s.invalidateCachesAfterTransaction(afterCtx, &models.Transaction{ID: job.ID, FromUserID: job.FromUserID, ToUserID: job.ToUserID, Type: job.Type})
+	} else if err != nil && !abandoned {
+		// the rolled back row is gone, the failure only shows in the stats
+		if statsErr := s.statsRepo.Record(s.transactionRepo.GetDB().WithContext(afterCtx), job.ID, job.SubmittedAt, job.Type, models.TxStatusFailed, job.Amount); statsErr != nil {
+			utils.Logger.Error("❌ TRANSACTION STATS " + job.ID.String() + " ERROR: " + statsErr.Error())
+		}
+	}
+
+	// only asynchronous jobs are dead-lettered, a synchronous caller always
+	// got an error back, often one telling it to retry, so replaying its job
+	// could apply it twice
+	if err != nil && job.Persisted && !isBusinessError(err) {
+		if job.Attempts > attempts {
+			attempts = job.Attempts
+		}
+		s.deadLetter(afterCtx, job, err, attempts)
+	}
+
+	if job.Persisted {
+		go s.sendCallback(job.ID)
 	}
 
     txType := job.Type.String()
@@ -120,13 +326,143 @@ func (s *TransactionService) processTransaction(job workers.TransactionJob) work
 		Error: err,
 	}
 }
-	
-func (s *TransactionService) processDeposit(tx *gorm.DB, transaction *models.Transaction) error {
+
+// retryDelay backs off exponentially from TX_RETRY_BASE_MS with jitter, so
+// transactions that collided do not collide again right away.
+func (s *TransactionService) retryDelay(attempt int) time.Duration {
+	delay := s.retryBaseDelay << (attempt - 1)
+	if delay > transactionRetryMaxDelay {
+		delay = transactionRetryMaxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isBusinessError tells apart errors of the transaction itself, which fail
+// it for good, from infrastructure errors.
+func isBusinessError(err error) bool {
+	for _, businessErr := range []error{
+		constants.ErrInsufficientBalance,
+		constants.ErrDailyLimitExceeded,
+		constants.ErrRecipientRestricted,
+		constants.ErrAccountFrozen,
+		constants.ErrAccountSuspended,
+		constants.ErrAccountClosed,
+	} {
+		if errors.Is(err, businessErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// markFailed fails the stored transaction of an asynchronous job and counts
+// it in the stats, unless another delivery finished it already.
+func (s *TransactionService) markFailed(ctx context.Context, job workers.TransactionJob, cause error) error {
+	return s.transactionRepo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		transaction, err := s.transactionRepo.MarkFailed(tx, job.ID, transactionFailureReason(cause))
+		if err != nil || transaction == nil {
+			return err
+		}
+		return s.statsRepo.Record(tx, transaction.ID, transaction.CreatedAt, transaction.Type, transaction.Status, transaction.Amount)
+	})
+}
+
+// requeueFailed puts a failed asynchronous transaction back into the durable
+// queue under its own id, so the IsFinal check in processTransaction still
+// keeps it from being applied twice. It refuses unless the stored
+// transaction is failed, a transaction that committed after all is left
+// alone.
+func (s *TransactionService) requeueFailed(tx *gorm.DB, id uuid.UUID, auditContext models.AuditContext) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.FindByIDForUpdate(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != models.TxStatusFailed {
+		return nil, errTransactionNotFailed
+	}
+
+	if err := s.statsRepo.Retract(tx, transaction.ID, transaction.CreatedAt, transaction.Type, transaction.Status, transaction.Amount); err != nil {
+		return nil, err
+	}
+
+	transaction.Status = models.TxStatusPending
+	transaction.FailureReason = nil
+	if err := s.transactionRepo.Update(tx, transaction); err != nil {
+		return nil, err
+	}
+
+	job := workers.TransactionJob{
+		ID: transaction.ID,
+		Type: transaction.Type,
+		FromUserID: transaction.FromUserID,
+		ToUserID: transaction.ToUserID,
+		Amount: transaction.Amount,
+		Audit: auditContext,
+	}
+
+	return transaction, s.jobQueue.Enqueue(tx, job)
+}
+
+// deadLetter keeps a job that failed for reasons other than the transaction
+// itself, so an admin can replay or discard it.
+func (s *TransactionService) deadLetter(ctx context.Context, job workers.TransactionJob, err error, attempts int) {
+	submittedAt := job.SubmittedAt
+	if submittedAt.IsZero() {
+		submittedAt = time.Now()
+	}
+
+	deadLetter := &models.TransactionDeadLetter{
+		JobID: job.ID,
+		Type: job.Type,
+		FromUserID: job.FromUserID,
+		ToUserID: job.ToUserID,
+		Amount: job.Amount,
+		AuditContext: job.Audit,
+		Async: job.Persisted,
+		Error: err.Error(),
+		Attempts: attempts,
+		Status: models.DeadLetterPending,
+		SubmittedAt: submittedAt,
+	}
+
+	if err := s.deadLetterRepo.Create(ctx, deadLetter); err != nil {
+		utils.Logger.Error("❌ DEAD LETTER TRANSACTION " + job.ID.String() + " ERROR: " + err.Error())
+		return
+	}
+
+	metrics.TransactionDeadLetteredTotal.Inc()
+	if err := UpdateDeadLetterBacklog(); err != nil {
+		utils.Logger.Error("❌ DEAD LETTER BACKLOG ERROR: " + err.Error())
+	}
+}
+
+func transactionFailureReason(err error) string {
+	switch {
+	case isBusinessError(err):
+		return err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		return "transaction timed out"
+	case errors.Is(err, context.Canceled):
+		return "transaction was cancelled"
+	case repository.RetryableCause(err) != "":
+		return "transaction conflicted with concurrent transactions"
+	case errors.Is(err, errJobDeliveriesExhausted):
+		return err.Error()
+	}
+	return "transaction could not be processed"
+}
+
+func (s *TransactionService) processDeposit(tx *gorm.DB, transaction *models.Transaction, auditContext models.AuditContext) error {
 	balance, err := s.balanceRepo.FindByUserIDForUpdate(tx, *transaction.ToUserID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
+	if err := s.recheckAccounts(tx, transaction); err != nil {
+		return err
+	}
+
 	previousAmount := 0.0
 	if balance != nil {
 		previousAmount = balance.Amount
@@ -144,35 +480,38 @@ func (s *TransactionService) processDeposit(tx *gorm.DB, transaction *models.Tra
 		return err
 	}
 
-	s.logBalanceChange(transaction.ToUserID, models.ActionDeposit, previousAmount, newBalance.Amount, transaction.Amount, nil, &transaction.ID)
-
-	return nil
+	return s.logBalanceChange(tx, auditContext, transaction.ToUserID, models.ActionDeposit, previousAmount, newBalance.Amount, transaction.Amount, nil, &transaction.ID)
 }
 
-func (s *TransactionService) processWithdraw(tx *gorm.DB, transaction *models.Transaction) error {
+func (s *TransactionService) processWithdraw(tx *gorm.DB, transaction *models.Transaction, auditContext models.AuditContext) error {
 	balance, err := s.balanceRepo.FindByUserIDForUpdate(tx, *transaction.FromUserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return constants.ErrInsufficientBalance
+	}
 	if err != nil {
 		return err
 	}
 
+	if err := s.recheckAccounts(tx, transaction); err != nil {
+		return err
+	}
+
 	previousAmount := balance.Amount
 	balance.Amount -= transaction.Amount
 	balance.LastUpdatedAt = time.Now()
 
 	if balance.Amount < 0 {
-		return errors.New("insufficient balance")
+		return constants.ErrInsufficientBalance
 	}
 
 	if err := s.balanceRepo.Update(tx, balance); err != nil {
 		return err
 	}
 
-	s.logBalanceChange(transaction.FromUserID, models.ActionWithdraw, previousAmount, balance.Amount, transaction.Amount, nil, &transaction.ID)
-
-	return nil
+	return s.logBalanceChange(tx, auditContext, transaction.FromUserID, models.ActionWithdraw, previousAmount, balance.Amount, transaction.Amount, nil, &transaction.ID)
 }
 
-func (s *TransactionService) processTransfer(tx *gorm.DB, transaction *models.Transaction) error {
+func (s *TransactionService) processTransfer(tx *gorm.DB, transaction *models.Transaction, auditContext models.AuditContext) error {
 	fromID := *transaction.FromUserID
 	toID := *transaction.ToUserID
 
@@ -200,8 +539,12 @@ func (s *TransactionService) processTransfer(tx *gorm.DB, transaction *models.Tr
 		fromBalance, toBalance = secondBalance, firstBalance
 	}
 
+	if err := s.recheckAccounts(tx, transaction); err != nil {
+		return err
+	}
+
 	if fromBalance == nil || fromBalance.Amount < transaction.Amount {
-		return errors.New("insufficient balance")
+		return constants.ErrInsufficientBalance
 	}
 
 	fromPrevious := fromBalance.Amount
@@ -226,13 +569,16 @@ func (s *TransactionService) processTransfer(tx *gorm.DB, transaction *models.Tr
 		return err
 	}
 
-	s.logBalanceChange(&fromID, models.ActionTransferOut, fromPrevious, fromBalance.Amount, transaction.Amount, &toID, &transaction.ID)
-	s.logBalanceChange(&toID, models.ActionTransferIn, toPrevious, newToBalance.Amount, transaction.Amount, &fromID, &transaction.ID)
+	if err := s.logBalanceChange(tx, auditContext, &fromID, models.ActionTransferOut, fromPrevious, fromBalance.Amount, transaction.Amount, &toID, &transaction.ID); err != nil {
+		return err
+	}
 
-	return nil
+	return s.logBalanceChange(tx, auditContext, &toID, models.ActionTransferIn, toPrevious, newToBalance.Amount, transaction.Amount, &fromID, &transaction.ID)
 }
 
-func (s *TransactionService) logBalanceChange(userID *uuid.UUID, action models.AuditAction, prev, new, change float64, relatedUserID, txID *uuid.UUID) {
+// logBalanceChange writes the audit row in the same database transaction as
+// the balance update, so a balance never changes without its audit entry.
+func (s *TransactionService) logBalanceChange(tx *gorm.DB, auditContext models.AuditContext, userID *uuid.UUID, action models.AuditAction, prev, new, change float64, relatedUserID, txID *uuid.UUID) error {
 	details := map[string]interface{}{
 		"previous_amount": prev,
 		"new_amount":      new,
@@ -246,12 +592,13 @@ func (s *TransactionService) logBalanceChange(userID *uuid.UUID, action models.A
 	}
 
 	detailsJSON, _ := json.Marshal(details)
-	go s.auditRepo.Create(&models.AuditLog{
+	return s.auditRepo.Create(tx, &models.AuditLog{
 		ID: uuid.New(),
 		EntityType: models.EntityBalance,
 		EntityID:   *userID,
 		Action:     action,
 		Details:    string(detailsJSON),
+		AuditContext: auditContext,
 	})
 }
 
@@ -260,19 +607,28 @@ func (s *TransactionService) Credit(ctx *fiber.Ctx, req dto.CreditRequest, userI
 		return utils.JsonErrorValidationFields(ctx, errs)
 	}
 
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	if !user.CanReceive(s.freezeBlocksAll) {
+		return utils.JsonErrorForbidden(ctx, accountRestrictedError(user))
+	}
+
+	if err := s.checkKycLimits(ctx.UserContext(), user, models.TxTypeDeposit, req.Amount); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
 	job := workers.TransactionJob{
 		ID: uuid.New(),
 		Type: models.TxTypeDeposit,
 		ToUserID: &userID,
 		Amount: req.Amount,
+		Audit: audit.FromRequest(ctx),
 	}
 
-	result := s.workerPool.SubmitAndWait(job)
-	if result.Error != nil {
-		return utils.JsonError(ctx, result.Error, "E_CREDIT_FAILED");
-	}
-
-	return utils.JsonSuccess(ctx, transformer.TransactionTransformer(result.Transaction))
+	return s.submit(ctx, job, req.CallbackURL, "E_CREDIT_FAILED")
 }
 
 func (s *TransactionService) Debit(ctx *fiber.Ctx, req dto.DebitRequest, userID uuid.UUID) error {
@@ -280,19 +636,19 @@ func (s *TransactionService) Debit(ctx *fiber.Ctx, req dto.DebitRequest, userID
 		return utils.JsonErrorValidationFields(ctx, errs)
 	}
 
+	if err := s.checkSender(ctx.UserContext(), userID, models.TxTypeWithdraw, req.Amount); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
 	job := workers.TransactionJob{
 		ID: uuid.New(),
 		Type: models.TxTypeWithdraw,
 		FromUserID: &userID,
 		Amount: req.Amount,
+		Audit: audit.FromRequest(ctx),
 	}
 
-	result := s.workerPool.SubmitAndWait(job)
-	if result.Error != nil {
-		return utils.JsonError(ctx, result.Error, "E_DEBIT_FAILED");
-	}
-
-	return utils.JsonSuccess(ctx, transformer.TransactionTransformer(result.Transaction))
+	return s.submit(ctx, job, req.CallbackURL, "E_DEBIT_FAILED")
 }
 
 func (s *TransactionService) Transfer(ctx *fiber.Ctx, req dto.TransferRequest, fromUserID uuid.UUID) error {
@@ -304,58 +660,368 @@ func (s *TransactionService) Transfer(ctx *fiber.Ctx, req dto.TransferRequest, f
 		return utils.JsonError(ctx, errors.New("cannot transfer to yourself"), "E_TRANSFER_SELF");
 	}
 
+	if err := s.checkSender(ctx.UserContext(), fromUserID, models.TxTypeTransfer, req.Amount); err != nil {
+		return utils.JsonErrorForbidden(ctx, err)
+	}
+
+	recipient, err := s.userRepo.FindByID(req.ToUserID)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("recipient not found"))
+	}
+
+	if recipient.IsClosed() {
+		return utils.JsonError(ctx, errors.New("recipient account is closed"), "E_RECIPIENT_CLOSED")
+	}
+
+	if !recipient.CanReceive(s.freezeBlocksAll) {
+		return utils.JsonError(ctx, constants.ErrRecipientRestricted, "E_RECIPIENT_RESTRICTED")
+	}
+
 	job := workers.TransactionJob{
 		ID: uuid.New(),
 		Type: models.TxTypeTransfer,
 		FromUserID: &fromUserID,
 		ToUserID: &req.ToUserID,
 		Amount: req.Amount,
+		Audit: audit.FromRequest(ctx),
+	}
+
+	return s.submit(ctx, job, req.CallbackURL, "E_TRANSFER_FAILED")
+}
+
+// submit runs the job on the worker pool and writes the response. When the
+// queue stays full for TX_QUEUE_WAIT_MS the request fails fast with
+// E_QUEUE_FULL instead of piling up behind it. Queueing and processing
+// together are bounded by TX_TIMEOUT_MS.
+func (s *TransactionService) submit(ctx *fiber.Ctx, job workers.TransactionJob, callbackURL string, failedCode string) error {
+	job.SubmittedAt = time.Now()
+	if isAsyncRequest(ctx) {
+		return s.submitAsync(ctx, job, callbackURL)
+	}
+
+	jobCtx, cancelJob := context.WithTimeout(ctx.UserContext(), s.processTimeout)
+	defer cancelJob()
+	job.Ctx = jobCtx
+
+	submitCtx, cancel := context.WithTimeout(jobCtx, s.queueWait)
+	defer cancel()
+
+	result, err := s.workerPool.SubmitAndWait(submitCtx, job)
+	if errors.Is(err, workers.ErrPoolStopped) {
+		return utils.JsonErrorUnavailable(ctx, err, "E_SHUTTING_DOWN", transactionRetryAfterSeconds)
+	}
+	if err != nil {
+		return utils.JsonErrorUnavailable(ctx, err, "E_QUEUE_FULL", transactionRetryAfterSeconds)
 	}
 
-	result := s.workerPool.SubmitAndWait(job)
-	if result.Error != nil {
-		return utils.JsonError(ctx, result.Error, "E_TRANSFER_FAILED");
+	switch {
+	case errors.Is(result.Error, context.DeadlineExceeded):
+		return s.timedOut(ctx, job.ID, failedCode)
+	case errors.Is(result.Error, context.Canceled):
+		return utils.JsonErrorUnavailable(ctx, errors.New("transaction was cancelled"), "E_TX_CANCELLED", transactionRetryAfterSeconds)
+	case repository.RetryableCause(result.Error) != "":
+		return utils.JsonErrorUnavailable(ctx, errors.New("transaction conflicted with concurrent transactions, try again"), "E_TX_CONFLICT", transactionRetryAfterSeconds)
+	case isBusinessError(result.Error):
+		return utils.JsonError(ctx, result.Error, failedCode)
+	case result.Error != nil:
+		return utils.JsonErrorInternal(ctx, result.Error, failedCode)
 	}
 
 	return utils.JsonSuccess(ctx, transformer.TransactionTransformer(result.Transaction))
 }
 
+// timedOut answers a synchronous transaction that ran out of time. The
+// deadline may have hit while COMMIT was in flight, so the transaction is
+// read back first: a final status is reported as it is, otherwise the
+// outcome is unknown and the client has to poll for it.
+func (s *TransactionService) timedOut(ctx *fiber.Ctx, id uuid.UUID, failedCode string) error {
+	readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx.UserContext()), transactionStatusReadTimeout)
+	defer cancel()
+
+	transaction, err := s.transactionRepo.FindByID(readCtx, id)
+	if err == nil {
+		switch transaction.Status {
+		case models.TxStatusCompleted:
+			return utils.JsonSuccess(ctx, transformer.TransactionTransformer(transaction))
+		case models.TxStatusFailed, models.TxStatusCancelled:
+			reason := "transaction " + transaction.Status.String()
+			if transaction.FailureReason != nil {
+				reason = *transaction.FailureReason
+			}
+			return utils.JsonError(ctx, errors.New(reason), failedCode)
+		}
+	}
+
+	ctx.Location("/api/v1/transactions/" + id.String())
+	return utils.JsonErrorTimeout(ctx, errors.New("transaction timed out, its outcome is unknown, poll GET /api/v1/transactions/"+id.String()), "E_TX_TIMEOUT")
+}
+
+func isAsyncRequest(ctx *fiber.Ctx) bool {
+	return ctx.QueryBool("async") || strings.Contains(ctx.Get("Prefer"), "respond-async")
+}
+
+// submitAsync stores the pending transaction together with its job in the
+// durable queue, so its status can be polled at GET /transactions/:id right
+// away and the job survives restarts, and responds without waiting for the
+// result.
+func (s *TransactionService) submitAsync(ctx *fiber.Ctx, job workers.TransactionJob, callbackURL string) error {
+	if callbackURL != "" {
+		if err := utils.ValidatePublicURL(ctx.UserContext(), callbackURL); err != nil {
+			return utils.JsonErrorValidation(ctx, errors.New("callback_url must be a public http or https url: "+err.Error()))
+		}
+	}
+
+	transaction := &models.Transaction{
+		ID: job.ID,
+		FromUserID: job.FromUserID,
+		ToUserID: job.ToUserID,
+		Amount: job.Amount,
+		Type: job.Type,
+		Status: models.TxStatusPending,
+	}
+	if callbackURL != "" {
+		transaction.CallbackURL = &callbackURL
+	}
+
+	err := s.transactionRepo.GetDB().WithContext(ctx.UserContext()).Transaction(func(tx *gorm.DB) error {
+		return s.enqueue(tx, transaction, job)
+	})
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_TRANSACTION_CREATE")
+	}
+
+	s.invalidateCachesAfterTransaction(ctx.UserContext(), transaction)
+	s.dispatcher.Wake()
+
+	if strings.Contains(ctx.Get("Prefer"), "respond-async") {
+		ctx.Set("Preference-Applied", "respond-async")
+	}
+
+	ctx.Location("/api/v1/transactions/" + job.ID.String())
+	return utils.JsonAccepted(ctx, transformer.TransactionTransformer(transaction))
+}
+
+// enqueue stores a pending transaction and its job in the durable queue
+// within tx. Call dispatcher.Wake once tx committed.
+func (s *TransactionService) enqueue(tx *gorm.DB, transaction *models.Transaction, job workers.TransactionJob) error {
+	if err := s.transactionRepo.Create(tx, transaction); err != nil {
+		return err
+	}
+	return s.jobQueue.Enqueue(tx, job)
+}
+
+// sendCallback posts the final state of an asynchronous transaction to its
+// callback url, as long as it resolves to a public address. With
+// TX_CALLBACK_SECRET set the body is signed with HMAC-SHA256 in the
+// X-Signature header.
+func (s *TransactionService) sendCallback(id uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), transactionCallbackTimeout)
+	transaction, err := s.transactionRepo.FindByID(ctx, id)
+	cancel()
+	if err != nil || transaction.CallbackURL == nil {
+		return
+	}
+
+	body, _ := json.Marshal(transformer.TransactionTransformer(transaction))
+
+	for attempt := 1; attempt <= transactionCallbackAttempts; attempt++ {
+		if err = postTransactionCallback(*transaction.CallbackURL, body); err == nil {
+			return
+		}
+
+		utils.Logger.Error("❌ TRANSACTION " + id.String() + " CALLBACK ATTEMPT " + strconv.Itoa(attempt) + " ERROR: " + err.Error())
+		if attempt < transactionCallbackAttempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+}
+
+func postTransactionCallback(url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), transactionCallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	if secret := os.Getenv("TX_CALLBACK_SECRET"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	// a 3xx counts as a failure, redirects are not followed
+	res, err := callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.New("callback responded with status " + strconv.Itoa(res.StatusCode))
+	}
+
+	return nil
+}
+
+// checkSender rejects money leaving a frozen, suspended or closed account
+// and applies the sender's kyc limits. Incoming money is governed by
+// FROZEN_ACCOUNT_POLICY (see User.CanReceive).
+func (s *TransactionService) checkSender(ctx context.Context, userID uuid.UUID, txType models.TransactionType, amount float64) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.CanSend() {
+		return accountRestrictedError(user)
+	}
+
+	return s.checkKycLimits(ctx, user, txType, amount)
+}
+
+func (s *TransactionService) checkKycLimits(ctx context.Context, user *models.User, txType models.TransactionType, amount float64) error {
+	policy := user.KycTier.Policy()
+	if !policy.Allows(txType) {
+		return errors.New(txType.String() + " requires a higher kyc tier")
+	}
+
+	if policy.MaxAmount > 0 && amount > policy.MaxAmount {
+		return errors.New("amount exceeds the limit of your kyc tier")
+	}
+
+	// pending transactions count as well, otherwise asynchronous submissions
+	// could queue any amount before the first one completes. This is early
+	// feedback only, concurrent submissions can pass it together; the
+	// worker enforces the limit under the balance lock in recheckAccounts.
+	if txType != models.TxTypeDeposit {
+		return s.checkDailyLimit(s.transactionRepo.GetDB().WithContext(ctx), user, amount, models.TxStatusCompleted, models.TxStatusPending)
+	}
+
+	return nil
+}
+
+func (s *TransactionService) checkDailyLimit(db *gorm.DB, user *models.User, amount float64, statuses ...models.TransactionStatus) error {
+	limit := user.KycTier.Policy().DailyOutgoingLimit
+	if limit <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	spent, err := s.transactionRepo.SumOutgoingSince(db, user.ID, startOfDay, statuses...)
+	if err != nil {
+		return err
+	}
+
+	if spent+amount > limit {
+		return constants.ErrDailyLimitExceeded
+	}
+
+	return nil
+}
+
+// recheckAccounts repeats the account checks of the submission inside the
+// processing transaction, once the balance rows are locked. A queued job may
+// run after its account was frozen, and only the balance lock keeps
+// concurrent transactions of one sender from passing the daily limit
+// together. The limit counts completed transactions here, the one being
+// processed is still pending.
+func (s *TransactionService) recheckAccounts(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.FromUserID != nil {
+		sender, err := s.userRepo.FindByIDForShare(tx, *transaction.FromUserID)
+		if err != nil {
+			return err
+		}
+
+		if !sender.CanSend() {
+			return accountRestrictedError(sender)
+		}
+
+		if err := s.checkDailyLimit(tx, sender, transaction.Amount, models.TxStatusCompleted); err != nil {
+			return err
+		}
+	}
+
+	if transaction.ToUserID != nil {
+		recipient, err := s.userRepo.FindByIDForShare(tx, *transaction.ToUserID)
+		if err != nil {
+			return err
+		}
+
+		if !recipient.CanReceive(s.freezeBlocksAll) {
+			if transaction.IsDeposit() {
+				return accountRestrictedError(recipient)
+			}
+			return constants.ErrRecipientRestricted
+		}
+	}
+
+	return nil
+}
+
+func accountRestrictedError(user *models.User) error {
+	switch user.EffectiveStatus() {
+	case models.UserStatusClosed:
+		return constants.ErrAccountClosed
+	case models.UserStatusSuspended:
+		return constants.ErrAccountSuspended
+	}
+	return constants.ErrAccountFrozen
+}
+
+// GetByID returns a transaction to its sender or recipient. Anyone else gets
+// the same 404 as for a missing transaction, so ids can not be probed.
 func (s *TransactionService) GetByID(ctx *fiber.Ctx, id uuid.UUID, userID uuid.UUID) error {
 	cacheKey := s.keyTransactionDetailCache(id)
-	cacheData := s.getTransactionDetailCache(cacheKey)
+	cacheData := s.getTransactionDetailCache(ctx.UserContext(), cacheKey)
 
 	if cacheData != nil {
-		if cacheData.FromUserID != nil && uuid.MustParse(*cacheData.FromUserID) != userID {
-			return utils.JsonErrorUnauthorized(ctx, errors.New("unauthorized access"))
+		if !isTransactionParty(cacheData, userID) {
+			return utils.JsonErrorNotFound(ctx, errors.New("transaction not found"))
 		}
 		return utils.JsonSuccess(ctx, cacheData)
 	}
 
-	transaction, err := s.transactionRepo.FindByID(id)
+	transaction, err := s.transactionRepo.FindByID(ctx.UserContext(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.JsonErrorNotFound(ctx, errors.New("transaction not found"))
+	}
 	if err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_TRANSACTION_NOT_FOUND")
 	}
 
-	if transaction.FromUserID != nil && *transaction.FromUserID != userID {
-		return utils.JsonErrorUnauthorized(ctx, errors.New("unauthorized access"))
+	response := transformer.TransactionTransformer(transaction)
+	if !isTransactionParty(&response, userID) {
+		return utils.JsonErrorNotFound(ctx, errors.New("transaction not found"))
 	}
 
-	response := transformer.TransactionTransformer(transaction)
-	s.setCache(cacheKey, response)
+	if transaction.IsFinal() {
+		s.setCache(ctx.UserContext(), cacheKey, response)
+	}
 
 	return utils.JsonSuccess(ctx, response)
 }
 
+func isTransactionParty(transaction *dto.TransactionResponse, userID uuid.UUID) bool {
+	id := userID.String()
+	return (transaction.FromUserID != nil && *transaction.FromUserID == id) ||
+		(transaction.ToUserID != nil && *transaction.ToUserID == id)
+}
+
 func (s *TransactionService) GetHistory(ctx *fiber.Ctx, userID uuid.UUID) error {
 	pagination := utils.GetPagination(ctx)
 	cacheKey := s.keyTransactionHistoryCache(userID, pagination.Page, pagination.Limit)
-	cacheData := s.getTransactionHistoryCache(cacheKey)
+	cacheData := s.getTransactionHistoryCache(ctx.UserContext(), cacheKey)
 
 	if cacheData != nil {
 		return utils.JsonSuccess(ctx, cacheData)
 	}
 
-	transactions, total, err := s.transactionRepo.FindByUserID(userID, pagination.Limit, pagination.GetOffset())
+	transactions, total, err := s.transactionRepo.FindByUserID(ctx.UserContext(), userID, pagination.Limit, pagination.GetOffset())
 	if err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_TRANSACTION_HISTORY")
 	}
@@ -365,31 +1031,68 @@ func (s *TransactionService) GetHistory(ctx *fiber.Ctx, userID uuid.UUID) error
 		pagination.Page, pagination.Limit, total,
 	)
 
-	s.setCache(cacheKey, response)
+	s.setCache(ctx.UserContext(), cacheKey, response)
 	return utils.JsonSuccess(ctx, response)
 }
 
-func (s *TransactionService) GetStats(ctx *fiber.Ctx) error {
-	cacheKey := constants.CacheTransactionStats
-	cacheData := s.getTransactionStatsCache(cacheKey)
+// GetStats reads the aggregates every instance maintains in the database,
+// by default the last 24 hours in hourly buckets. Hourly buckets span at
+// most transactionStatsMaxHours, daily ones transactionStatsMaxDays.
+func (s *TransactionService) GetStats(ctx *fiber.Ctx, req dto.TransactionStatsRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	filter := repository.TransactionStatsFilter{
+		To:       time.Now(),
+		Interval: req.Interval,
+	}
+	if filter.Interval == "" {
+		filter.Interval = "hour"
+	}
+
+	if req.To != "" {
+		filter.To, _ = time.Parse(time.RFC3339, req.To)
+	}
 
-	if cacheData != nil {
-		return utils.JsonSuccess(ctx, cacheData)
+	filter.From = filter.To.Add(-24 * time.Hour)
+	if req.From != "" {
+		filter.From, _ = time.Parse(time.RFC3339, req.From)
+	}
+	filter.From = filter.From.Truncate(time.Hour)
+
+	maxRange := transactionStatsMaxHours * time.Hour
+	if filter.Interval == "day" {
+		maxRange = transactionStatsMaxDays * 24 * time.Hour
+	}
+
+	if !filter.From.Before(filter.To) {
+		return utils.JsonErrorValidation(ctx, errors.New("from must be before to"))
+	}
+	if filter.To.Sub(filter.From) > maxRange {
+		return utils.JsonErrorValidation(ctx, errors.New("range is too large for "+filter.Interval+" buckets"))
+	}
+
+	if req.Type != "" {
+		for txType := models.TxTypeDeposit; txType.IsValid(); txType++ {
+			if txType.String() == req.Type {
+				filter.Type = txType
+			}
+		}
+	}
+
+	stats, err := s.statsRepo.Aggregate(ctx.UserContext(), filter)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_TRANSACTION_STATS")
 	}
 
-	stats := s.workerPool.GetStats()
-	response := dto.TransactionStatsResponse{
-		TotalProcessed:   stats.TotalProcessed,
-		TotalSuccessful:  stats.TotalSuccessful,
-		TotalFailed:      stats.TotalFailed,
+	queue := dto.TransactionQueueStatsResponse{
+		InstanceID:       workers.InstanceID(),
 		PendingInQueue:   s.workerPool.QueueLength(),
-		TotalCredited:    float64(stats.TotalCredited) / 100,
-		TotalDebited:     float64(stats.TotalDebited) / 100,
-		TotalTransferred: float64(stats.TotalTransferred) / 100,
+		ShardQueueDepths: s.workerPool.ShardQueueLengths(),
 	}
 
-	s.setCache(cacheKey, response)
-	return utils.JsonSuccess(ctx, response)
+	return utils.JsonSuccess(ctx, transformer.TransactionStatsTransformer(stats, filter.From, filter.To, filter.Interval, queue))
 }
 
 func (s *TransactionService) keyTransactionDetailCache(id uuid.UUID) string {
@@ -401,12 +1104,15 @@ func (s *TransactionService) keyTransactionHistoryCache(userID uuid.UUID, page,
 		strconv.Itoa(page) + "_" + strconv.Itoa(limit)
 }
 
-func (s *TransactionService) getTransactionDetailCache(key string) *dto.TransactionResponse {
+func (s *TransactionService) getTransactionDetailCache(ctx context.Context, key string) *dto.TransactionResponse {
 	if s.redisStorage == nil {
 		return nil
 	}
 
-	data, err := s.redisStorage.Get(key)
+	ctx, cancel := context.WithTimeout(ctx, transactionCacheTimeout)
+	defer cancel()
+
+	data, err := s.redisStorage.Conn().Get(ctx, key).Bytes()
 	if err != nil || len(data) == 0 {
 		utils.Logger.Info("❌ NO CACHE TRANSACTION DETAIL FOUND FOR KEY " + key)
 		return nil
@@ -422,12 +1128,15 @@ func (s *TransactionService) getTransactionDetailCache(key string) *dto.Transact
 	return &response
 }
 
-func (s *TransactionService) getTransactionHistoryCache(key string) *dto.PaginatedResponse[dto.TransactionResponse] {
+func (s *TransactionService) getTransactionHistoryCache(ctx context.Context, key string) *dto.PaginatedResponse[dto.TransactionResponse] {
 	if s.redisStorage == nil {
 		return nil
 	}
 
-	data, err := s.redisStorage.Get(key)
+	ctx, cancel := context.WithTimeout(ctx, transactionCacheTimeout)
+	defer cancel()
+
+	data, err := s.redisStorage.Conn().Get(ctx, key).Bytes()
 	if err != nil || len(data) == 0 {
 		utils.Logger.Info("❌ NO CACHE TRANSACTION HISTORY FOUND FOR KEY " + key)
 		return nil
@@ -443,29 +1152,7 @@ func (s *TransactionService) getTransactionHistoryCache(key string) *dto.Paginat
 	return &response
 }
 
-func (s *TransactionService) getTransactionStatsCache(key string) *dto.TransactionStatsResponse {
-	if s.redisStorage == nil {
-		return nil
-	}
-
-	data, err := s.redisStorage.Get(key)
-	if err != nil || len(data) == 0 {
-		utils.Logger.Info("❌ NO CACHE TRANSACTION STATS FOUND FOR KEY " + key)
-		return nil
-	}
-
-	var response dto.TransactionStatsResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		utils.Logger.Error("Error unmarshaling transaction stats cache: " + err.Error())
-		return nil
-	}
-
-	utils.Logger.Info("GET CACHE TRANSACTION STATS FROM KEY " + key)
-	return &response
-}
-
-
-func (s *TransactionService) setCache(key string, data interface{}) {
+func (s *TransactionService) setCache(ctx context.Context, key string, data interface{}) {
 	if s.redisStorage == nil {
 		utils.Logger.Error("❌ REDIS STORAGE IS NULL")
 		return
@@ -477,93 +1164,93 @@ func (s *TransactionService) setCache(key string, data interface{}) {
 		return
 	}
 
-	if err := s.redisStorage.Set(key, dataJSON, 12 * time.Hour); err != nil {
+	ctx, cancel := context.WithTimeout(ctx, transactionCacheTimeout)
+	defer cancel()
+
+	if err := s.redisStorage.Conn().Set(ctx, key, dataJSON, 12 * time.Hour).Err(); err != nil {
 		utils.Logger.Error("❌ REDIS KEY " + key + " ERROR: " + err.Error())
 	}
 
 	utils.Logger.Info("SET CACHE TRANSACTION LIST TO KEY " + key)
 }
 
-func (s *TransactionService) invalidateCachesAfterTransaction(transaction *models.Transaction) {
-	s.invalidateTransactionCache(transaction.ID, transaction.FromUserID)
+func (s *TransactionService) invalidateCachesAfterTransaction(ctx context.Context, transaction *models.Transaction) {
+	s.invalidateTransactionCache(ctx, transaction.ID, transaction.FromUserID)
 
 	if transaction.Type == models.TxTypeTransfer && transaction.ToUserID != nil {
-		s.invalidateTransactionCache(transaction.ID, transaction.ToUserID)
+		s.invalidateTransactionCache(ctx, transaction.ID, transaction.ToUserID)
 	}
 
 	switch transaction.Type {
 	case models.TxTypeDeposit:
 		if transaction.ToUserID != nil {
-			InvalidateBalanceCacheForUser(*transaction.ToUserID)
+			InvalidateBalanceCacheForUser(ctx, *transaction.ToUserID)
 		}
 	
 	case models.TxTypeWithdraw:
 		if transaction.FromUserID != nil {
-			InvalidateBalanceCacheForUser(*transaction.FromUserID)
+			InvalidateBalanceCacheForUser(ctx, *transaction.FromUserID)
 		}
 	
 	case models.TxTypeTransfer:
 		if transaction.FromUserID != nil {
-			InvalidateBalanceCacheForUser(*transaction.FromUserID)
+			InvalidateBalanceCacheForUser(ctx, *transaction.FromUserID)
 		}
 		if transaction.ToUserID != nil && transaction.FromUserID != nil &&
 			*transaction.ToUserID != *transaction.FromUserID {
-			InvalidateBalanceCacheForUser(*transaction.ToUserID)
+			InvalidateBalanceCacheForUser(ctx, *transaction.ToUserID)
 		}
 	}
 }
 
-func InvalidateBalanceCacheForUser(userID uuid.UUID) {
+func InvalidateBalanceCacheForUser(ctx context.Context, userID uuid.UUID) {
 	if configs.RedisStorage == nil {
 		return
 	}
 
-	currentKey := constants.CacheBalanceCurrent + "_" + userID.String()
-	configs.RedisStorage.Delete(currentKey)
+	keys := []string{constants.CacheBalanceCurrent + "_" + userID.String()}
 
 	commonPages := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 	commonLimits := []int{10, 20, 25, 50, 100}
 
-	deletedCount := 0
 	for _, page := range commonPages {
 		for _, limit := range commonLimits {
-			key := constants.CacheBalanceHistory + "_" + userID.String() + "_" +
-				strconv.Itoa(page) + "_" + strconv.Itoa(limit)
-			if err := configs.RedisStorage.Delete(key); err == nil {
-				deletedCount++
-			}
+			keys = append(keys, constants.CacheBalanceHistory + "_" + userID.String() + "_" +
+				strconv.Itoa(page) + "_" + strconv.Itoa(limit))
 		}
 	}
 
+	deletedCount, err := configs.RedisStorage.Conn().Del(ctx, keys...).Result()
+	if err != nil {
+		utils.Logger.Error("❌ INVALIDATE BALANCE CACHE FOR USER " + userID.String() + " ERROR: " + err.Error())
+		return
+	}
+
 	utils.Logger.Info("INVALIDATE BALANCE CACHE FOR USER " + userID.String() + 
-	" - Deleted " + strconv.Itoa(deletedCount+1) + " cache keys")
+	" - Deleted " + strconv.FormatInt(deletedCount, 10) + " cache keys")
 }
 
-func (s *TransactionService) invalidateTransactionCache(transactionID uuid.UUID, userID *uuid.UUID) {
+func (s *TransactionService) invalidateTransactionCache(ctx context.Context, transactionID uuid.UUID, userID *uuid.UUID) {
 	if s.redisStorage == nil {
 		return
 	}
 
-	detailKey := s.keyTransactionDetailCache(transactionID)
-	s.redisStorage.Delete(detailKey)
-
-	s.redisStorage.Delete(constants.CacheTransactionStats)
+	keys := []string{s.keyTransactionDetailCache(transactionID)}
 
 	if userID != nil {
 		commonPages := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 		commonLimits := []int{10, 20, 25, 50, 100}
 
-		deletedCount := 0
 		for _, page := range commonPages {
 			for _, limit := range commonLimits {
-				key := s.keyTransactionHistoryCache(*userID, page, limit)
-				if err := s.redisStorage.Delete(key); err == nil {
-					deletedCount++
-				}
+				keys = append(keys, s.keyTransactionHistoryCache(*userID, page, limit))
 			}
 		}
+	}
 
-		utils.Logger.Info("INVALIDATE TRANSACTION CACHE FOR USER " + userID.String() + " - Deleted " + strconv.Itoa(deletedCount) + " history cache keys")
+	if err := s.redisStorage.Conn().Del(ctx, keys...).Err(); err != nil {
+		utils.Logger.Error("❌ INVALIDATE TRANSACTION CACHE FOR TX " + transactionID.String() + " ERROR: " + err.Error())
+		return
 	}
 
 	utils.Logger.Info("INVALIDATE TRANSACTION CACHE FOR TX " + transactionID.String())
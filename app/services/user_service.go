@@ -1,53 +1,101 @@
 package services
 
 import (
+	"backend-path/app/audit"
 	"backend-path/app/dto"
+	"backend-path/app/mailer"
+	"backend-path/app/middlewares"
 	"backend-path/app/models"
 	"backend-path/app/repository"
 	"backend-path/app/transformer"
 	"backend-path/configs"
 	"backend-path/constants"
 	"backend-path/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/storage/redis"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type IUserService interface {
-	GetAll(ctx *fiber.Ctx) error
+	GetAll(ctx *fiber.Ctx, req dto.UserFilterRequest) error
 	GetByID(ctx *fiber.Ctx, id uuid.UUID) error
 	Update(ctx *fiber.Ctx, id uuid.UUID, req dto.UpdateUserRequest) error
 	Delete(ctx *fiber.Ctx, id uuid.UUID) error
+	Unlock(ctx *fiber.Ctx, id uuid.UUID) error
+	UpdateMe(ctx *fiber.Ctx, id uuid.UUID, req dto.UpdateProfileRequest) error
+	VerifyEmail(ctx *fiber.Ctx, id uuid.UUID, req dto.VerifyEmailRequest) error
+	Close(ctx *fiber.Ctx, id uuid.UUID, req dto.CloseAccountRequest) error
+	Restore(ctx *fiber.Ctx, id uuid.UUID) error
+	Freeze(ctx *fiber.Ctx, id uuid.UUID, req dto.ChangeAccountStatusRequest) error
+	Suspend(ctx *fiber.Ctx, id uuid.UUID, req dto.ChangeAccountStatusRequest) error
+	Reactivate(ctx *fiber.Ctx, id uuid.UUID, req dto.ReactivateAccountRequest) error
+	PurgeDeleted() error
 }
 
+var errBalanceNotZero = errors.New("balance is not zero")
+
+var errPendingTransactions = errors.New("account has pending transactions")
+
 type UserService struct {
 	userRepo repository.IUserRepository
+	auditOutbox audit.IOutbox
+	roleRepo repository.IRoleRepository
+	balanceRepo repository.IBalanceRepository
+	transactionRepo repository.ITransactionRepository
+	apiKeyRepo repository.IApiKeyRepository
+	sessionService ISessionService
+	loginAttempts ILoginAttemptService
+	mailer mailer.Mailer
 	redisStorage *redis.Storage
 }
 
 func NewUserService() *UserService {
 	return &UserService{
 		userRepo: repository.NewUserRepository(),
+		auditOutbox: audit.NewOutbox(),
+		roleRepo: repository.NewRoleRepository(),
+		balanceRepo: repository.NewBalanceRepository(),
+		transactionRepo: repository.NewTransactionRepository(),
+		apiKeyRepo: repository.NewApiKeyRepository(),
+		sessionService: NewSessionService(),
+		loginAttempts: NewLoginAttemptService(),
+		mailer: mailer.NewMailer(),
 		redisStorage: configs.RedisStorage,
 	}
 }
 
 
-func (s *UserService) GetAll(ctx *fiber.Ctx) error {
+func (s *UserService) GetAll(ctx *fiber.Ctx, req dto.UserFilterRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
 	pagination := utils.GetPagination(ctx)
 
-	cacheKey := s.keyUserListCache(pagination.Page, pagination.Limit)
+	cacheKey := s.keyUserListCache(req, pagination.Page, pagination.Limit)
 	cacheData := s.getUserListCache(cacheKey)
 	if cacheData != nil {
 		return utils.JsonSuccess(ctx, cacheData)
 	}
 
-	users, total, err := s.userRepo.FindAll(pagination.Limit, pagination.GetOffset())
+	filter, err := s.userFilter(req)
+	if err != nil {
+		return utils.JsonErrorValidation(ctx, err)
+	}
+
+	users, total, err := s.userRepo.FindAll(filter, pagination.Limit, pagination.GetOffset())
 	if err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_USER_LIST")
 	}
@@ -66,6 +114,48 @@ func (s *UserService) GetAll(ctx *fiber.Ctx) error {
 	return utils.JsonSuccess(ctx, response)
 }
 
+func (s *UserService) userFilter(req dto.UserFilterRequest) (repository.UserFilter, error) {
+	filter := repository.UserFilter{
+		Query: strings.TrimSpace(req.Q),
+		Sort:  req.Sort,
+		Desc:  req.Order != "asc",
+	}
+
+	if req.Role != "" {
+		role, err := s.roleRepo.FindByName(req.Role)
+		if err != nil {
+			return filter, errors.New("role not found")
+		}
+		filter.RoleID = &role.ID
+	}
+
+	if req.Status != "" {
+		for status := models.UserStatusActive; status.IsValid(); status++ {
+			if status.String() == req.Status {
+				filter.Status = status
+			}
+		}
+	}
+
+	if req.CreatedFrom != "" {
+		from, _ := time.Parse(time.DateOnly, req.CreatedFrom)
+		filter.CreatedFrom = &from
+	}
+
+	// created_to is inclusive, the repository compares against the next day
+	if req.CreatedTo != "" {
+		to, _ := time.Parse(time.DateOnly, req.CreatedTo)
+		to = to.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, errors.New("created_from must not be after created_to")
+	}
+
+	return filter, nil
+}
+
 func (s *UserService) GetByID(ctx *fiber.Ctx, id uuid.UUID) error {
 	cacheKey := s.keyUserDetailCache(id.String())
 	cacheData := s.getUserDetailCache(cacheKey)
@@ -98,6 +188,9 @@ func (s *UserService) Update(ctx *fiber.Ctx, id uuid.UUID, req dto.UpdateUserReq
 		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
 	}
 
+	before := userProfileFields(user)
+	previousRole := user.RoleID
+
 	if req.Username != "" {
 		user.Username = req.Username
 	}
@@ -107,13 +200,57 @@ func (s *UserService) Update(ctx *fiber.Ctx, id uuid.UUID, req dto.UpdateUserReq
 	}
 
 	if req.Role != "" {
-		user.RoleID = models.ToRole(req.Role)
+		role, err := s.roleRepo.FindByName(req.Role)
+		if err != nil {
+			return utils.JsonErrorValidation(ctx, errors.New("role not found"))
+		}
+		user.RoleID = role.ID
+	}
+
+	if user.RoleID != previousRole {
+		if ctx.Locals("user_auth").(string) == id.String() {
+			return utils.JsonErrorForbidden(ctx, errors.New("cannot change your own role"))
+		}
+
+		// the caller has to sit above both the role taken away and the one
+		// granted, so no one can hand out a role as strong as their own
+		for _, role := range []models.Role{previousRole, user.RoleID} {
+			outranks, err := middlewares.Outranks(ctx, role)
+			if err != nil {
+				return utils.JsonErrorInternal(ctx, err, "E_PERMISSION_RESOLVE")
+			}
+
+			if !outranks {
+				return utils.JsonErrorForbidden(ctx, errors.New("cannot change the role of an account with an equal or higher role, or grant one"))
+			}
+		}
 	}
 
 	if err := s.userRepo.Update(user); err != nil {
 		return utils.JsonErrorInternal(ctx, err, "E_USER_UPDATE")
 	}
 
+	if changes := audit.Diff(before, userProfileFields(user)); changes != "" {
+		s.logUserChanges(ctx, id, models.ActionUpdate, changes)
+	}
+
+	if user.RoleID != previousRole {
+		// tokens carry the role, they end with the sessions so the new role
+		// applies from the next login; api keys are issued for the old role
+		if err := s.sessionService.RevokeAll(id); err != nil {
+			utils.Logger.Error("❌ REVOKE SESSIONS ERROR: " + err.Error())
+		}
+
+		if err := s.apiKeyRepo.RevokeAllByUserID(id); err != nil {
+			utils.Logger.Error("❌ REVOKE API KEYS ERROR: " + err.Error())
+		}
+
+		s.logUserChanges(ctx, id, models.ActionRoleChange, audit.Diff(
+			map[string]interface{}{"role_id": previousRole, "role": previousRole.String()},
+			map[string]interface{}{"role_id": user.RoleID, "role": user.RoleID.String()},
+		))
+	}
+
 	userDetail := transformer.UserTransformer(user)
 	cacheKey := s.keyUserDetailCache(id.String())
 	s.setCache(cacheKey, userDetail)
@@ -129,7 +266,8 @@ func (s *UserService) Delete(ctx *fiber.Ctx, id uuid.UUID) error {
 		return utils.JsonErrorForbidden(ctx, errors.New("cannot delete yourself"))
 	}
 
-	if _, err := s.userRepo.FindByID(id); err != nil {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
 		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
 	}
 
@@ -137,6 +275,20 @@ func (s *UserService) Delete(ctx *fiber.Ctx, id uuid.UUID) error {
 		return utils.JsonErrorInternal(ctx, err, "E_USER_DELETE")
 	}
 
+	if err := s.sessionService.RevokeAll(id); err != nil {
+		utils.Logger.Error("❌ REVOKE SESSIONS ERROR: " + err.Error())
+	}
+
+	if err := s.apiKeyRepo.RevokeAllByUserID(id); err != nil {
+		utils.Logger.Error("❌ REVOKE API KEYS ERROR: " + err.Error())
+	}
+
+	s.logUser(ctx, id, models.ActionDelete, map[string]interface{}{
+		"soft_delete": true,
+		"username":    user.Username,
+		"email":       user.Email,
+		"role":        user.RoleID.String(),
+	})
 
 	cacheKey := s.keyUserDetailCache(id.String())
 	s.redisStorage.Delete(cacheKey)
@@ -146,8 +298,460 @@ func (s *UserService) Delete(ctx *fiber.Ctx, id uuid.UUID) error {
 	return utils.JsonSuccess(ctx, fiber.Map{"message": "user deleted"})
 }
 
-func (s *UserService) keyUserListCache(page int, limit int) string {
-	return constants.CacheUserList + "_" + strconv.Itoa(page) + "_" + strconv.Itoa(limit)
+func (s *UserService) Restore(ctx *fiber.Ctx, id uuid.UUID) error {
+	user, err := s.userRepo.FindDeletedByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("deleted user not found"))
+	}
+
+	if s.userRepo.IsExist(user.Email) {
+		return utils.JsonError(ctx, errors.New("email is used by another account"), "E_RESTORE_CONFLICT")
+	}
+
+	if s.userRepo.IsUsernameExist(user.Username) {
+		return utils.JsonError(ctx, errors.New("username is used by another account"), "E_RESTORE_CONFLICT")
+	}
+
+	if err := s.userRepo.Restore(id); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_USER_RESTORE")
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	s.logUser(ctx, id, models.ActionRestore, map[string]interface{}{})
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+}
+
+// PurgeDeleted permanently removes users that were soft deleted longer than
+// the retention period ago. Users with transactions or money on their
+// balance are kept so financial history stays intact.
+func (s *UserService) PurgeDeleted() error {
+	retentionDays, _ := strconv.Atoi(os.Getenv("USER_PURGE_RETENTION_DAYS"))
+	if retentionDays == 0 {
+		retentionDays = 30
+	}
+
+	deletedBefore := time.Now().AddDate(0, 0, -retentionDays)
+	users, err := s.userRepo.FindPurgeable(deletedBefore, 100)
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, user := range users {
+		if err := s.userRepo.Purge(user.ID); err != nil {
+			utils.Logger.Error("❌ PURGE USER " + user.ID.String() + " ERROR: " + err.Error())
+			continue
+		}
+
+		detailsJSON, _ := json.Marshal(map[string]interface{}{
+			"deleted_at": user.DeletedAt.Time.Format(constants.TimestampFormat),
+		})
+		s.auditOutbox.Record(&models.AuditLog{
+			EntityType: models.EntityUser,
+			EntityID:   user.ID,
+			Action:     models.ActionPurge,
+			Details:    string(detailsJSON),
+		})
+		purged++
+	}
+
+	utils.Logger.Info("✅ PURGED " + strconv.Itoa(purged) + " DELETED USERS")
+	return nil
+}
+
+func (s *UserService) Unlock(ctx *fiber.Ctx, id uuid.UUID) error {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	s.loginAttempts.Reset(user.Email)
+
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"email": user.Email,
+	})
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     user.ID,
+		Action:       models.ActionUnlock,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "user unlocked"})
+}
+
+func (s *UserService) Freeze(ctx *fiber.Ctx, id uuid.UUID, req dto.ChangeAccountStatusRequest) error {
+	return s.changeStatus(ctx, id, models.UserStatusFrozen, models.ActionFreeze, req)
+}
+
+func (s *UserService) Suspend(ctx *fiber.Ctx, id uuid.UUID, req dto.ChangeAccountStatusRequest) error {
+	return s.changeStatus(ctx, id, models.UserStatusSuspended, models.ActionSuspend, req)
+}
+
+func (s *UserService) Reactivate(ctx *fiber.Ctx, id uuid.UUID, req dto.ReactivateAccountRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	outranks, err := middlewares.Outranks(ctx, user.RoleID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_PERMISSION_RESOLVE")
+	}
+
+	if !outranks {
+		return utils.JsonErrorForbidden(ctx, errors.New("cannot change the status of an account with an equal or higher role"))
+	}
+
+	previous := user.EffectiveStatus()
+	if previous != models.UserStatusFrozen && previous != models.UserStatusSuspended {
+		return utils.JsonError(ctx, errors.New("account is not frozen or suspended"), "E_ACCOUNT_NOT_RESTRICTED")
+	}
+
+	actorID, _ := uuid.Parse(ctx.Locals("user_auth").(string))
+	user.Status = models.UserStatusActive
+	user.StatusReason = nil
+	user.StatusExpiresAt = nil
+	user.StatusChangedBy = &actorID
+
+	if err := s.userRepo.Update(user); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_USER_STATUS")
+	}
+
+	middlewares.InvalidateAccountStatusCache(id.String())
+	s.logUser(ctx, id, models.ActionReactivate, map[string]interface{}{
+		"from":   previous.String(),
+		"to":     models.UserStatusActive.String(),
+		"reason": req.Reason,
+	})
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+}
+
+// changeStatus freezes or suspends an account. Without expires_at the
+// restriction stays until the account is reactivated.
+func (s *UserService) changeStatus(ctx *fiber.Ctx, id uuid.UUID, status models.UserStatus, action models.AuditAction, req dto.ChangeAccountStatusRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	currentUserID := ctx.Locals("user_auth").(string)
+	if currentUserID == id.String() {
+		return utils.JsonErrorForbidden(ctx, errors.New("cannot change your own account status"))
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return utils.JsonErrorValidation(ctx, errors.New("expires_at must be in the future"))
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	if user.IsClosed() {
+		return utils.JsonError(ctx, constants.ErrAccountClosed, "E_ACCOUNT_CLOSED")
+	}
+
+	outranks, err := middlewares.Outranks(ctx, user.RoleID)
+	if err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_PERMISSION_RESOLVE")
+	}
+
+	if !outranks {
+		return utils.JsonErrorForbidden(ctx, errors.New("cannot change the status of an account with an equal or higher role"))
+	}
+
+	actorID, _ := uuid.Parse(currentUserID)
+	previous := user.EffectiveStatus()
+	user.Status = status
+	user.StatusReason = &req.Reason
+	user.StatusExpiresAt = req.ExpiresAt
+	user.StatusChangedBy = &actorID
+
+	if err := s.userRepo.Update(user); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_USER_STATUS")
+	}
+
+	middlewares.InvalidateAccountStatusCache(id.String())
+
+	details := map[string]interface{}{
+		"from":   previous.String(),
+		"to":     status.String(),
+		"reason": req.Reason,
+	}
+	if req.ExpiresAt != nil {
+		details["expires_at"] = req.ExpiresAt.Format(constants.TimestampFormat)
+	}
+	s.logUser(ctx, id, action, details)
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+}
+
+func (s *UserService) UpdateMe(ctx *fiber.Ctx, id uuid.UUID, req dto.UpdateProfileRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	changes := map[string]interface{}{}
+
+	if req.Username != "" && req.Username != user.Username {
+		if s.userRepo.IsUsernameExist(req.Username) {
+			return utils.JsonErrorValidation(ctx, errors.New("username already exist"))
+		}
+		changes["username"] = map[string]string{"from": user.Username, "to": req.Username}
+		user.Username = req.Username
+	}
+
+	var verificationToken string
+	if req.Email != "" && NormalizeEmail(req.Email) != NormalizeEmail(user.Email) {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+			return utils.JsonErrorValidation(ctx, errors.New("current password is wrong"))
+		}
+
+		if s.userRepo.IsExist(req.Email) {
+			return utils.JsonErrorValidation(ctx, constants.ErrEmailExist)
+		}
+
+		verificationToken, err = utils.RandomHex(32)
+		if err != nil {
+			return utils.JsonErrorInternal(ctx, err, "E_TOKEN_GENERATE")
+		}
+
+		tokenHash := utils.HashToken(verificationToken)
+		expiresAt := time.Now().Add(emailVerificationTTL())
+		user.PendingEmail = &req.Email
+		user.EmailVerificationHash = &tokenHash
+		user.EmailVerificationExpiresAt = &expiresAt
+		changes["pending_email"] = req.Email
+	}
+
+	if len(changes) == 0 {
+		return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_USER_UPDATE")
+	}
+
+	if verificationToken != "" {
+		body := "Use this token to confirm your new email address: " + verificationToken
+		if err := s.mailer.Send(*user.PendingEmail, "Confirm your new email address", body); err != nil {
+			utils.Logger.Error("❌ SEND EMAIL VERIFICATION ERROR: " + err.Error())
+		}
+	}
+
+	s.logUser(ctx, user.ID, models.ActionUpdate, changes)
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+}
+
+func (s *UserService) VerifyEmail(ctx *fiber.Ctx, id uuid.UUID, req dto.VerifyEmailRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	if user.PendingEmail == nil || user.EmailVerificationHash == nil || user.EmailVerificationExpiresAt == nil {
+		return utils.JsonError(ctx, errors.New("no pending email change"), "E_NO_PENDING_EMAIL")
+	}
+
+	if time.Now().After(*user.EmailVerificationExpiresAt) {
+		return utils.JsonError(ctx, errors.New("verification token expired"), "E_TOKEN_EXPIRED")
+	}
+
+	if !utils.CompareTokenHash(req.Token, *user.EmailVerificationHash) {
+		return utils.JsonErrorValidation(ctx, errors.New("invalid verification token"))
+	}
+
+	if s.userRepo.IsExist(*user.PendingEmail) {
+		return utils.JsonErrorValidation(ctx, constants.ErrEmailExist)
+	}
+
+	previousEmail := user.Email
+	user.Email = *user.PendingEmail
+	user.PendingEmail = nil
+	user.EmailVerificationHash = nil
+	user.EmailVerificationExpiresAt = nil
+
+	if err := s.userRepo.Update(user); err != nil {
+		return utils.JsonErrorInternal(ctx, err, "E_USER_UPDATE")
+	}
+
+	s.loginAttempts.Reset(previousEmail)
+	s.logUser(ctx, user.ID, models.ActionUpdate, map[string]interface{}{
+		"email": map[string]string{"from": previousEmail, "to": user.Email},
+	})
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, transformer.UserTransformer(user))
+}
+
+func (s *UserService) Close(ctx *fiber.Ctx, id uuid.UUID, req dto.CloseAccountRequest) error {
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return utils.JsonErrorValidationFields(ctx, errs)
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return utils.JsonErrorNotFound(ctx, errors.New("user not found"))
+	}
+
+	if user.IsClosed() {
+		return utils.JsonError(ctx, errors.New("account already closed"), "E_ACCOUNT_CLOSED")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return utils.JsonErrorValidation(ctx, errors.New("password is wrong"))
+	}
+
+	closedAt := time.Now()
+	user.Status = models.UserStatusClosed
+	user.ClosedAt = &closedAt
+	user.PendingEmail = nil
+	user.EmailVerificationHash = nil
+	user.EmailVerificationExpiresAt = nil
+
+	err = s.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := lockClosable(tx, s.balanceRepo, s.transactionRepo, id); err != nil {
+			return err
+		}
+		return s.userRepo.Save(tx, user)
+	})
+	switch {
+	case errors.Is(err, errBalanceNotZero):
+		return utils.JsonError(ctx, errors.New("balance must be zero before closing the account"), "E_BALANCE_NOT_ZERO")
+	case errors.Is(err, errPendingTransactions):
+		return utils.JsonError(ctx, errors.New("account has pending transactions"), "E_PENDING_TRANSACTIONS")
+	case err != nil:
+		return utils.JsonErrorInternal(ctx, err, "E_ACCOUNT_CLOSE")
+	}
+
+	if err := s.sessionService.RevokeAll(id); err != nil {
+		utils.Logger.Error("❌ REVOKE SESSIONS ERROR: " + err.Error())
+	}
+
+	if err := s.apiKeyRepo.RevokeAllByUserID(id); err != nil {
+		utils.Logger.Error("❌ REVOKE API KEYS ERROR: " + err.Error())
+	}
+
+	s.logUser(ctx, user.ID, models.ActionClose, map[string]interface{}{
+		"reason": req.Reason,
+	})
+	s.refreshUserCache(user)
+
+	return utils.JsonSuccess(ctx, fiber.Map{"message": "account closed"})
+}
+
+// lockClosable locks the balance of the user within tx and checks that the
+// account can be closed: a zero balance and no pending transactions.
+// Transaction workers take the same lock and recheck the account status
+// under it, so none can move money once tx closed the account.
+func lockClosable(tx *gorm.DB, balanceRepo repository.IBalanceRepository, transactionRepo repository.ITransactionRepository, userID uuid.UUID) error {
+	balance, err := balanceRepo.FindByUserIDForUpdate(tx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err == nil && balance.Amount != 0 {
+		return errBalanceNotZero
+	}
+
+	pending, err := transactionRepo.CountPendingByUserID(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return errPendingTransactions
+	}
+
+	return nil
+}
+
+func (s *UserService) logUser(ctx *fiber.Ctx, userID uuid.UUID, action models.AuditAction, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     userID,
+		Action:       action,
+		Details:      string(detailsJSON),
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+func (s *UserService) logUserChanges(ctx *fiber.Ctx, userID uuid.UUID, action models.AuditAction, changes string) {
+	s.auditOutbox.Record(&models.AuditLog{
+		EntityType:   models.EntityUser,
+		EntityID:     userID,
+		Action:       action,
+		Details:      "{}",
+		Changes:      changes,
+		AuditContext: audit.FromRequest(ctx),
+	})
+}
+
+func userProfileFields(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"username": user.Username,
+		"email":    user.Email,
+	}
+}
+
+func (s *UserService) refreshUserCache(user *models.User) {
+	s.setCache(s.keyUserDetailCache(user.ID.String()), transformer.UserTransformer(user))
+	s.resetUserListCache()
+}
+
+func emailVerificationTTL() time.Duration {
+	hours, _ := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL_HOURS"))
+	if hours == 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// keyUserListCache namespaces list entries by the current list version, so
+// bumping the version invalidates every filter combination at once. Entries
+// of older versions simply expire.
+func (s *UserService) keyUserListCache(req dto.UserFilterRequest, page int, limit int) string {
+	filterJSON, _ := json.Marshal(req)
+	hash := sha256.Sum256(filterJSON)
+
+	return constants.CacheUserList + "_v" + s.userListVersion() + "_" +
+		hex.EncodeToString(hash[:8]) + "_" + strconv.Itoa(page) + "_" + strconv.Itoa(limit)
+}
+
+func (s *UserService) userListVersion() string {
+	if s.redisStorage == nil {
+		return "0"
+	}
+
+	version, err := s.redisStorage.Get(constants.CacheUserListVersion)
+	if err != nil || len(version) == 0 {
+		return "0"
+	}
+
+	return string(version)
 }
 
 func (s *UserService) keyUserDetailCache(uuid string) string {
@@ -209,18 +813,11 @@ func (s *UserService) resetUserListCache() {
 		return
 	}
 
-	commonPages := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 25, 30, 40, 50}
-	commonLimits := []int{10, 20, 25, 50, 100}
-	
-	deletedCount := 0
-	for _, page := range commonPages {
-		for _, limit := range commonLimits {
-			key := s.keyUserListCache(page, limit)
-			if err := s.redisStorage.Delete(key); err == nil {
-				deletedCount++
-			}
-		}
+	version, err := s.redisStorage.Conn().Incr(context.Background(), constants.CacheUserListVersion).Result()
+	if err != nil {
+		utils.Logger.Error("❌ RESET CACHE USER LIST ERROR: " + err.Error())
+		return
 	}
-	
-	utils.Logger.Info("✅ RESET CACHE USER LIST - Deleted " + strconv.Itoa(deletedCount) + " cache keys")
-}
\ No newline at end of file
+
+	utils.Logger.Info("✅ RESET CACHE USER LIST - version " + strconv.FormatInt(version, 10))
+}
@@ -0,0 +1,224 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrNoSigningKey   = errors.New("no active signing key")
+	ErrUnexpectedAlg  = errors.New("unexpected signing algorithm")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrVerifyOnlyKey  = errors.New("key cannot be used for signing")
+)
+
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	privateKey interface{}
+	publicKey  interface{}
+}
+
+func (k *SigningKey) CanSign() bool {
+	return k.privateKey != nil
+}
+
+// IsPublic reports whether the verification key may be published in the JWKS.
+// Shared HMAC secrets never are.
+func (k *SigningKey) IsPublic() bool {
+	switch k.publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return true
+	}
+	return false
+}
+
+type KeyStore struct {
+	Issuer   string
+	Audience string
+
+	keys     map[string]*SigningKey
+	activeID string
+	legacy   *SigningKey
+	mu       sync.RWMutex
+}
+
+func NewKeyStore(issuer, audience string) *KeyStore {
+	return &KeyStore{
+		Issuer:   issuer,
+		Audience: audience,
+		keys:     make(map[string]*SigningKey),
+	}
+}
+
+func NewHMACKey(kid string, secret []byte) *SigningKey {
+	return &SigningKey{
+		ID:         kid,
+		Method:     jwt.SigningMethodHS256,
+		privateKey: secret,
+		publicKey:  secret,
+	}
+}
+
+// ParsePEMKey accepts an RSA or EC key in PEM form. Private keys can sign and
+// verify, public keys only verify (used for keys that are being retired).
+func ParsePEMKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data for key " + kid)
+	}
+
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, privateKey: key, publicKey: &key.PublicKey}, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		method, err := ecMethod(&key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{ID: kid, Method: method, privateKey: key, publicKey: &key.PublicKey}, nil
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, publicKey: key}, nil
+	case *ecdsa.PublicKey:
+		method, err := ecMethod(key)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{ID: kid, Method: method, publicKey: key}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+func ecMethod(key *ecdsa.PublicKey) (jwt.SigningMethod, error) {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return jwt.SigningMethodES256, nil
+	case 384:
+		return jwt.SigningMethodES384, nil
+	case 521:
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func (s *KeyStore) Add(key *SigningKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = key
+}
+
+func (s *KeyStore) Remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, kid)
+	if s.activeID == kid {
+		s.activeID = ""
+	}
+}
+
+// SetLegacy registers the key verifying tokens without a kid header, signed
+// before keys were named. They are still accepted until they expire.
+func (s *KeyStore) SetLegacy(key *SigningKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.legacy = key
+}
+
+func (s *KeyStore) SetActive(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if !key.CanSign() {
+		return ErrVerifyOnlyKey
+	}
+
+	s.activeID = kid
+	return nil
+}
+
+func (s *KeyStore) ActiveID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.activeID
+}
+
+func (s *KeyStore) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	key, ok := s.keys[s.activeID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.privateKey)
+}
+
+// Keyfunc resolves the verification key by the token's kid header, the
+// legacy key for tokens without one, and makes sure the token was signed
+// with the algorithm registered for that key.
+func (s *KeyStore) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, hasKid := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	if !hasKid {
+		key, ok = s.legacy, s.legacy != nil
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnexpectedAlg
+	}
+
+	return key.publicKey, nil
+}
+
+func (s *KeyStore) JWKS() JWKSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if !key.IsPublic() {
+			continue
+		}
+		if jwk, ok := toJWK(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,38 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/constants"
+	"time"
+)
+
+func ApiKeyTransformer(apiKey *models.ApiKey) dto.ApiKeyResponse {
+	return dto.ApiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		LastUsedAt: formatOptionalTime(apiKey.LastUsedAt),
+		ExpiresAt:  formatOptionalTime(apiKey.ExpiresAt),
+		RevokedAt:  formatOptionalTime(apiKey.RevokedAt),
+		CreatedAt:  apiKey.CreatedAt.Format(constants.TimestampFormat),
+	}
+}
+
+func ApiKeyListTransformer(apiKeys []models.ApiKey) []dto.ApiKeyResponse {
+	result := make([]dto.ApiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		result[i] = ApiKeyTransformer(&apiKey)
+	}
+	return result
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format(constants.TimestampFormat)
+	return &formatted
+}
@@ -0,0 +1,46 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/constants"
+	"encoding/json"
+)
+
+func AuditLogTransformer(log *models.AuditLog) dto.AuditLogResponse {
+	details := json.RawMessage(log.Details)
+	if len(details) == 0 {
+		details = json.RawMessage("{}")
+	}
+
+	response := dto.AuditLogResponse{
+		ID:          log.ID,
+		EntityType:  log.EntityType.String(),
+		EntityID:    log.EntityID,
+		Action:      log.Action.String(),
+		Details:     details,
+		ActorUserID: log.ActorUserID,
+		ActorRole:   log.ActorRole,
+		RequestID:   log.RequestID,
+		TraceID:     log.TraceID,
+		IP:          log.IP,
+		CreatedAt:   log.CreatedAt.Format(constants.TimestampFormat),
+		Seq:         log.Seq,
+		Hash:        log.Hash,
+		Redacted:    log.IsRedacted(),
+	}
+
+	if log.Changes != "" {
+		response.Changes = json.RawMessage(log.Changes)
+	}
+
+	return response
+}
+
+func AuditLogListTransformer(logs []models.AuditLog) []dto.AuditLogResponse {
+	response := make([]dto.AuditLogResponse, 0, len(logs))
+	for _, log := range logs {
+		response = append(response, AuditLogTransformer(&log))
+	}
+	return response
+}
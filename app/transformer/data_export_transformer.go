@@ -0,0 +1,24 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/constants"
+)
+
+func DataExportTransformer(export *models.DataExport) dto.DataExportResponse {
+	response := dto.DataExportResponse{
+		ID:        export.ID,
+		Status:    export.Status.String(),
+		Size:      export.Size,
+		ExpiresAt: export.ExpiresAt.Format(constants.TimestampFormat),
+		CreatedAt: export.CreatedAt.Format(constants.TimestampFormat),
+	}
+
+	if export.CompletedAt != nil {
+		completedAt := export.CompletedAt.Format(constants.TimestampFormat)
+		response.CompletedAt = &completedAt
+	}
+
+	return response
+}
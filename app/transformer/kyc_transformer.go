@@ -0,0 +1,59 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/constants"
+)
+
+func KycSubmissionTransformer(submission *models.KycSubmission) dto.KycSubmissionResponse {
+	response := dto.KycSubmissionResponse{
+		ID:             submission.ID,
+		UserID:         submission.UserID,
+		RequestedTier:  submission.RequestedTier.String(),
+		Status:         submission.Status.String(),
+		FullName:       submission.FullName,
+		Country:        submission.Country,
+		DocumentType:   submission.DocumentType,
+		DocumentNumber: submission.DocumentNumber,
+		ReviewerID:     submission.ReviewerID,
+		ReviewNote:     submission.ReviewNote,
+		CreatedAt:      submission.CreatedAt.Format(constants.TimestampFormat),
+	}
+
+	if submission.DateOfBirth != nil {
+		response.DateOfBirth = submission.DateOfBirth.Format("2006-01-02")
+	}
+
+	if submission.User != nil {
+		response.Username = submission.User.Username
+	}
+
+	if submission.ReviewedAt != nil {
+		reviewedAt := submission.ReviewedAt.Format(constants.TimestampFormat)
+		response.ReviewedAt = &reviewedAt
+	}
+
+	return response
+}
+
+func KycSubmissionListTransformer(submissions []models.KycSubmission) []dto.KycSubmissionResponse {
+	response := make([]dto.KycSubmissionResponse, 0, len(submissions))
+	for _, submission := range submissions {
+		response = append(response, KycSubmissionTransformer(&submission))
+	}
+	return response
+}
+
+func KycPolicyTransformer(policy models.KycPolicy) dto.KycPolicyResponse {
+	allowedTypes := make([]string, 0, len(policy.AllowedTypes))
+	for _, txType := range policy.AllowedTypes {
+		allowedTypes = append(allowedTypes, txType.String())
+	}
+
+	return dto.KycPolicyResponse{
+		AllowedTypes:       allowedTypes,
+		MaxAmount:          policy.MaxAmount,
+		DailyOutgoingLimit: policy.DailyOutgoingLimit,
+	}
+}
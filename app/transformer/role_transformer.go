@@ -0,0 +1,35 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+)
+
+func RoleTransformer(role *models.RoleDefinition) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          uint(role.ID),
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.ID.IsSystem(),
+		Permissions: role.PermissionNames(),
+	}
+}
+
+func RoleListTransformer(roles []models.RoleDefinition) []dto.RoleResponse {
+	result := make([]dto.RoleResponse, len(roles))
+	for i, role := range roles {
+		result[i] = RoleTransformer(&role)
+	}
+	return result
+}
+
+func PermissionListTransformer(permissions []models.Permission) []dto.PermissionResponse {
+	result := make([]dto.PermissionResponse, len(permissions))
+	for i, permission := range permissions {
+		result[i] = dto.PermissionResponse{
+			Name:        permission.Name,
+			Description: permission.Description,
+		}
+	}
+	return result
+}
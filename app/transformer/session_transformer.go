@@ -0,0 +1,28 @@
+package transformer
+
+import (
+	"backend-path/app/dto"
+	"backend-path/app/models"
+	"backend-path/constants"
+)
+
+func SessionTransformer(session *models.Session, currentID string) dto.SessionResponse {
+	return dto.SessionResponse{
+		ID:             session.ID,
+		Device:         session.Device,
+		IP:             session.IP,
+		UserAgent:      session.UserAgent,
+		Current:        session.ID.String() == currentID,
+		LastActivityAt: session.LastActivityAt.Format(constants.TimestampFormat),
+		ExpiresAt:      session.ExpiresAt.Format(constants.TimestampFormat),
+		CreatedAt:      session.CreatedAt.Format(constants.TimestampFormat),
+	}
+}
+
+func SessionListTransformer(sessions []models.Session, currentID string) []dto.SessionResponse {
+	result := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		result[i] = SessionTransformer(&session, currentID)
+	}
+	return result
+}
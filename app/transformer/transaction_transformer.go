@@ -4,6 +4,7 @@ import (
 	"backend-path/app/dto"
 	"backend-path/app/models"
 	"backend-path/constants"
+	"time"
 )
 
 func TransactionTransformer(tx *models.Transaction) dto.TransactionResponse {
@@ -13,6 +14,7 @@ func TransactionTransformer(tx *models.Transaction) dto.TransactionResponse {
 		Type:      tx.Type.String(),
 		Status:    tx.Status.String(),
 		CreatedAt: tx.CreatedAt.Format(constants.TimestampFormat),
+		FailureReason: tx.FailureReason,
 	}
 
 	if tx.FromUserID != nil {
@@ -33,4 +35,79 @@ func TransactionListTransformer(transactions []models.Transaction) []dto.Transac
 		result[i] = TransactionTransformer(&tx)
 	}
 	return result
-}
\ No newline at end of file
+}
+
+func TransactionDeadLetterTransformer(deadLetter *models.TransactionDeadLetter) dto.TransactionDeadLetterResponse {
+	response := dto.TransactionDeadLetterResponse{
+		ID:                    deadLetter.ID,
+		JobID:                 deadLetter.JobID,
+		Type:                  deadLetter.Type.String(),
+		FromUserID:            deadLetter.FromUserID,
+		ToUserID:              deadLetter.ToUserID,
+		Amount:                deadLetter.Amount,
+		Async:                 deadLetter.Async,
+		Error:                 deadLetter.Error,
+		Attempts:              deadLetter.Attempts,
+		Status:                deadLetter.Status.String(),
+		RequestID:             deadLetter.AuditContext.RequestID,
+		ReplayedTransactionID: deadLetter.ReplayedTransactionID,
+		ResolvedBy:            deadLetter.ResolvedBy,
+		SubmittedAt:           deadLetter.SubmittedAt.Format(constants.TimestampFormat),
+		CreatedAt:             deadLetter.CreatedAt.Format(constants.TimestampFormat),
+	}
+
+	if deadLetter.ResolvedAt != nil {
+		resolvedAt := deadLetter.ResolvedAt.Format(constants.TimestampFormat)
+		response.ResolvedAt = &resolvedAt
+	}
+
+	return response
+}
+
+func TransactionDeadLetterListTransformer(deadLetters []models.TransactionDeadLetter) []dto.TransactionDeadLetterResponse {
+	response := make([]dto.TransactionDeadLetterResponse, 0, len(deadLetters))
+	for i := range deadLetters {
+		response = append(response, TransactionDeadLetterTransformer(&deadLetters[i]))
+	}
+	return response
+}
+
+// TransactionStatsTransformer sums the buckets up into the totals. Amounts
+// count completed transactions only.
+func TransactionStatsTransformer(stats []models.TransactionStatsHourly, from, to time.Time, interval string, queue dto.TransactionQueueStatsResponse) dto.TransactionStatsResponse {
+	response := dto.TransactionStatsResponse{
+		From:     from.Format(constants.TimestampFormat),
+		To:       to.Format(constants.TimestampFormat),
+		Interval: interval,
+		Buckets:  make([]dto.TransactionStatsBucketResponse, len(stats)),
+		Queue:    queue,
+	}
+
+	for i, stat := range stats {
+		response.Buckets[i] = dto.TransactionStatsBucketResponse{
+			Bucket: stat.Bucket.Format(constants.TimestampFormat),
+			Type:   stat.Type.String(),
+			Status: stat.Status.String(),
+			Count:  stat.Count,
+			Amount: stat.Amount,
+		}
+
+		response.TotalProcessed += stat.Count
+		if stat.Status != models.TxStatusCompleted {
+			response.TotalFailed += stat.Count
+			continue
+		}
+
+		response.TotalSuccessful += stat.Count
+		switch stat.Type {
+		case models.TxTypeDeposit:
+			response.TotalCredited += stat.Amount
+		case models.TxTypeWithdraw:
+			response.TotalDebited += stat.Amount
+		case models.TxTypeTransfer:
+			response.TotalTransferred += stat.Amount
+		}
+	}
+
+	return response
+}
@@ -7,12 +7,28 @@ import (
 )
 
 func UserTransformer(user *models.User) dto.UserResponse {
+	status := user.EffectiveStatus()
+
+	var statusReason, statusExpiresAt *string
+	if status == models.UserStatusFrozen || status == models.UserStatusSuspended {
+		statusReason = user.StatusReason
+		if user.StatusExpiresAt != nil {
+			expiresAt := user.StatusExpiresAt.Format(constants.TimestampFormat)
+			statusExpiresAt = &expiresAt
+		}
+	}
+
 	return dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.RoleID.String(),
-		CreatedAt: user.CreatedAt.Format(constants.TimestampFormat),
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		PendingEmail: user.PendingEmail,
+		Role:         user.RoleID.String(),
+		Status:       status.String(),
+		KycTier:      user.KycTier.String(),
+		StatusReason:    statusReason,
+		StatusExpiresAt: statusExpiresAt,
+		CreatedAt:    user.CreatedAt.Format(constants.TimestampFormat),
 	}
 }
 
@@ -0,0 +1,142 @@
+package workers
+
+import (
+	"backend-path/utils"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobDispatcher feeds jobs from the durable queue into the worker pool and
+// removes them from the queue once processed. Jobs cut off by a shutdown are
+// released back to the queue.
+type JobDispatcher struct {
+	queue        JobQueue
+	pool         *TransactionWorkerPool
+	batchSize    int
+	pollInterval time.Duration
+	wake         chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	done         chan struct{}
+	inflight     sync.WaitGroup
+}
+
+func NewJobDispatcher(queue JobQueue, pool *TransactionWorkerPool, batchSize int) *JobDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &JobDispatcher{
+		queue:        queue,
+		pool:         pool,
+		batchSize:    batchSize,
+		pollInterval: time.Second,
+		wake:         make(chan struct{}, 1),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start requeues jobs left in flight by a previous run and starts
+// dispatching.
+func (d *JobDispatcher) Start() {
+	recovered, err := d.queue.Recover()
+	if err != nil {
+		utils.Logger.Error("❌ TRANSACTION JOB RECOVERY ERROR: " + err.Error())
+	} else if recovered > 0 {
+		utils.Logger.Info("✅ RECOVERED " + strconv.FormatInt(recovered, 10) + " TRANSACTION JOBS LEFT IN FLIGHT")
+	}
+
+	go d.run()
+}
+
+// Wake makes the dispatcher look for new jobs right away instead of at the
+// next poll.
+func (d *JobDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *JobDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := d.dispatch()
+		if claimed == d.batchSize {
+			continue
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *JobDispatcher) dispatch() int {
+	if d.ctx.Err() != nil {
+		return 0
+	}
+
+	jobs, err := d.queue.Claim(d.batchSize)
+	if err != nil {
+		utils.Logger.Error("❌ TRANSACTION JOB CLAIM ERROR: " + err.Error())
+		return 0
+	}
+
+	for _, job := range jobs {
+		job.Ctx = context.Background()
+		job.ResultChan = make(chan TransactionResult, 1)
+
+		if err := d.pool.Submit(d.ctx, job); err != nil {
+			d.release(job)
+			continue
+		}
+
+		d.inflight.Add(1)
+		go d.await(job)
+	}
+
+	return len(jobs)
+}
+
+func (d *JobDispatcher) await(job TransactionJob) {
+	defer d.inflight.Done()
+
+	result := <-job.ResultChan
+	if errors.Is(result.Error, context.Canceled) {
+		d.release(job)
+		return
+	}
+
+	if err := d.queue.Complete(job.ID); err != nil {
+		utils.Logger.Error("❌ TRANSACTION JOB " + job.ID.String() + " COMPLETE ERROR: " + err.Error())
+	}
+}
+
+func (d *JobDispatcher) release(job TransactionJob) {
+	if err := d.queue.Release(job.ID); err != nil {
+		utils.Logger.Error("❌ TRANSACTION JOB " + job.ID.String() + " RELEASE ERROR: " + err.Error())
+	}
+}
+
+// Stop stops claiming jobs. Jobs already handed to the pool are settled by
+// Wait once the pool stopped.
+func (d *JobDispatcher) Stop() {
+	d.cancel()
+	<-d.done
+}
+
+// Wait blocks until every dispatched job was completed or released.
+func (d *JobDispatcher) Wait() {
+	d.inflight.Wait()
+}
@@ -0,0 +1,110 @@
+package workers
+
+import (
+	"backend-path/app/models"
+	"backend-path/app/repository"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobQueue keeps accepted transaction jobs until they were processed.
+// Delivery is at least once, a job can be claimed again after a crash, so
+// processing has to be idempotent on the job id.
+type JobQueue interface {
+	Enqueue(tx *gorm.DB, job TransactionJob) error
+	Claim(limit int) ([]TransactionJob, error)
+	Complete(id uuid.UUID) error
+	Release(id uuid.UUID) error
+	Recover() (int64, error)
+}
+
+// PostgresJobQueue stores jobs in the transaction_jobs table. Instances
+// claim jobs with SELECT ... FOR UPDATE SKIP LOCKED and hold them for a
+// lease, TX_JOB_LEASE_SECONDS, after which other instances may take over.
+type PostgresJobQueue struct {
+	repo  repository.ITransactionJobRepository
+	owner string
+	lease time.Duration
+}
+
+func NewPostgresJobQueue() *PostgresJobQueue {
+	leaseSeconds, _ := strconv.Atoi(os.Getenv("TX_JOB_LEASE_SECONDS"))
+	if leaseSeconds == 0 {
+		leaseSeconds = 300
+	}
+
+	return &PostgresJobQueue{
+		repo:  repository.NewTransactionJobRepository(),
+		owner: InstanceID(),
+		lease: time.Duration(leaseSeconds) * time.Second,
+	}
+}
+
+// InstanceID names this instance as owner of claimed jobs, configured with
+// INSTANCE_ID and defaulting to the hostname. It has to survive restarts
+// for Recover to find the jobs left behind.
+func InstanceID() string {
+	if id := os.Getenv("INSTANCE_ID"); id != "" {
+		return id
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}
+
+// Enqueue stores the job within tx, the transaction that also stores its
+// pending transaction row.
+func (q *PostgresJobQueue) Enqueue(tx *gorm.DB, job TransactionJob) error {
+	return q.repo.Create(tx, &models.TransactionJobRecord{
+		ID:           job.ID,
+		Type:         job.Type,
+		FromUserID:   job.FromUserID,
+		ToUserID:     job.ToUserID,
+		Amount:       job.Amount,
+		AuditContext: job.Audit,
+		Status:       models.TxJobQueued,
+	})
+}
+
+func (q *PostgresJobQueue) Claim(limit int) ([]TransactionJob, error) {
+	records, err := q.repo.Claim(q.owner, limit, q.lease)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]TransactionJob, len(records))
+	for i, record := range records {
+		jobs[i] = TransactionJob{
+			ID:          record.ID,
+			Type:        record.Type,
+			FromUserID:  record.FromUserID,
+			ToUserID:    record.ToUserID,
+			Amount:      record.Amount,
+			Audit:       record.AuditContext,
+			Persisted:   true,
+			Attempts:    record.Attempts,
+			SubmittedAt: record.CreatedAt,
+		}
+	}
+
+	return jobs, nil
+}
+
+func (q *PostgresJobQueue) Complete(id uuid.UUID) error {
+	return q.repo.Delete(id, q.owner)
+}
+
+func (q *PostgresJobQueue) Release(id uuid.UUID) error {
+	return q.repo.Release(id, q.owner)
+}
+
+func (q *PostgresJobQueue) Recover() (int64, error) {
+	return q.repo.Recover(q.owner)
+}
@@ -0,0 +1,119 @@
+package workers
+
+import (
+	"backend-path/app/repository"
+	"backend-path/utils"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PeriodicWorker runs a maintenance task on a fixed interval until stopped.
+type PeriodicWorker struct {
+	name      string
+	interval  time.Duration
+	task      func() error
+	exclusive bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+	running   bool
+	mu        sync.Mutex
+}
+
+func NewPeriodicWorker(name string, interval time.Duration, task func() error) *PeriodicWorker {
+	return &PeriodicWorker{
+		name:     name,
+		interval: interval,
+		task:     task,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// NewExclusiveWorker is a PeriodicWorker whose task runs on one instance at
+// a time. Every run takes a database advisory lock named after the worker
+// and is skipped while another instance holds it.
+func NewExclusiveWorker(name string, interval time.Duration, task func() error) *PeriodicWorker {
+	worker := NewPeriodicWorker(name, interval, task)
+	worker.exclusive = true
+	return worker
+}
+
+func (w *PeriodicWorker) Name() string {
+	return w.name
+}
+
+func (w *PeriodicWorker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return
+	}
+	w.running = true
+
+	w.wg.Add(1)
+	go w.loop()
+
+	utils.Logger.Info("Periodic worker " + w.name + " started, every " + w.interval.String())
+}
+
+func (w *PeriodicWorker) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			w.run()
+		}
+	}
+}
+
+func (w *PeriodicWorker) run() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logger.Error("Periodic worker "+w.name+" panicked", zap.Any("panic", r))
+		}
+	}()
+
+	if w.exclusive {
+		unlock, locked, err := repository.TryAdvisoryLock("periodic_worker:" + w.name)
+		if err != nil {
+			utils.Logger.Error("Periodic worker "+w.name+" failed to lock", zap.Error(err))
+			return
+		}
+
+		if !locked {
+			utils.Logger.Info("Periodic worker " + w.name + " skipped, running on another instance")
+			return
+		}
+		defer unlock()
+	}
+
+	startTime := time.Now()
+	if err := w.task(); err != nil {
+		utils.Logger.Error("Periodic worker "+w.name+" failed", zap.Error(err))
+		return
+	}
+
+	utils.Logger.Info("Periodic worker " + w.name + " finished in " + time.Since(startTime).String())
+}
+
+func (w *PeriodicWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	close(w.stopChan)
+	w.wg.Wait()
+	utils.Logger.Info("Periodic worker " + w.name + " stopped")
+}
@@ -1,8 +1,13 @@
 package workers
 
 import (
+	"backend-path/app/metrics"
 	"backend-path/app/models"
 	"backend-path/utils"
+	"context"
+	"errors"
+	"hash/fnv"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,12 +15,27 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrQueueFull is returned when a job could not be queued before the
+// submission context ended.
+var ErrQueueFull = errors.New("transaction queue is full, try again later")
+
+// ErrPoolStopped is returned for jobs submitted after Stop was called.
+var ErrPoolStopped = errors.New("transaction workers are shutting down, try again later")
+
 type TransactionJob struct {
+	// Ctx carries the caller's deadline and trace into processing
+	Ctx context.Context
 	ID uuid.UUID
 	Type models.TransactionType
 	FromUserID *uuid.UUID
 	ToUserID *uuid.UUID
 	Amount float64
+	Audit models.AuditContext
+	// Persisted is set when the pending transaction was stored before queueing
+	Persisted bool
+	// Attempts counts deliveries of a persisted job, including this one
+	Attempts int
+	SubmittedAt time.Time
 	ResultChan chan TransactionResult
 }
 
@@ -33,19 +53,63 @@ type TransactionStats struct {
 	TotalTransferred int64
 }
 
+// TransactionWorkerPool runs every job on the worker that owns its account,
+// chosen by hashing the account id. Jobs of one account therefore run one
+// after another in submission order and never compete for the same balance
+// row. A transfer between accounts of two shards is queued on both and runs
+// once both workers reached it, so neither shard processes anything else
+// for those accounts in the meantime.
 type TransactionWorkerPool struct {
-	jobQueue chan TransactionJob
+	shards []chan shardJob
 	workerCount int
 	wg sync.WaitGroup
 	stats *TransactionStats
 	processor func(job TransactionJob) TransactionResult
+	pending int64
+	// crossShardMu keeps transfers spanning two shards in the same order on
+	// every shard, otherwise two shards could wait for each other
+	crossShardMu sync.Mutex
 	running bool
 	mu sync.RWMutex
+	// ctx is cancelled when Stop gives up waiting, aborting jobs in flight
+	ctx context.Context
+	cancel context.CancelFunc
+}
+
+type shardJob struct {
+	job TransactionJob
+	barrier *shardBarrier
+	primary bool
+	enqueuedAt time.Time
+}
+
+// shardBarrier joins the two workers of a cross-shard transfer. The
+// secondary worker announces it arrived and waits until the primary worker
+// has processed the job. When the secondary shard had no room the barrier is
+// cancelled and the primary worker drops the job.
+type shardBarrier struct {
+	arrived chan struct{}
+	done chan struct{}
+	cancelled bool
 }
 
 func NewTransactionWorkerPool(workerCount, queueSize int, processor func(job TransactionJob) TransactionResult) *TransactionWorkerPool {
+	shardSize := queueSize / workerCount
+	if shardSize < 1 {
+		shardSize = 1
+	}
+
+	shards := make([]chan shardJob, workerCount)
+	for i := range shards {
+		shards[i] = make(chan shardJob, shardSize)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &TransactionWorkerPool{
-		jobQueue: make(chan TransactionJob, queueSize),
+		ctx: ctx,
+		cancel: cancel,
+		shards: shards,
 		workerCount: workerCount,
 		stats: &TransactionStats{},
 		processor: processor,
@@ -68,30 +132,163 @@ func (p *TransactionWorkerPool) Start() {
 		go p.worker(i)
 	}
 
-	utils.Logger.Info("Transaction worker pool started with " + string(rune(p.workerCount+'0')) + " workers")
+	utils.Logger.Info("Transaction worker pool started with " + strconv.Itoa(p.workerCount) + " sharded workers")
 }
 
 func (p *TransactionWorkerPool) worker(id int) {
 	defer p.wg.Done()
 
-	for job := range p.jobQueue {
+	shard := strconv.Itoa(id)
+	for item := range p.shards[id] {
+		metrics.TransactionShardQueueDepth.WithLabelValues(shard).Set(float64(len(p.shards[id])))
 		startTime := time.Now()
-		result := p.processor(job)
+		metrics.TransactionQueueWaitDuration.Observe(startTime.Sub(item.enqueuedAt).Seconds())
+
+		if item.barrier != nil && !item.primary {
+			close(item.barrier.arrived)
+			<-item.barrier.done
+			metrics.TransactionShardJobDuration.WithLabelValues(shard).Observe(time.Since(startTime).Seconds())
+			continue
+		}
+
+		if item.barrier != nil {
+			<-item.barrier.arrived
+			if item.barrier.cancelled {
+				continue
+			}
+		}
+
+		job := item.job
+		processStart := time.Now()
+		result := p.process(job)
+		metrics.TransactionProcessingDuration.Observe(time.Since(processStart).Seconds())
 
+		if item.barrier != nil {
+			close(item.barrier.done)
+		}
+
+		metrics.TransactionQueueDepth.Set(float64(atomic.AddInt64(&p.pending, -1)))
 		atomic.AddInt64(&p.stats.TotalProcessed, 1)
+		status := "success"
 		if result.Error == nil {
 			atomic.AddInt64(&p.stats.TotalSuccessful, 1)
 			p.updateAmountStats(job)
 		} else {
 			atomic.AddInt64(&p.stats.TotalFailed, 1)
+			status = "failed"
 		}
 
+		metrics.TransactionShardJobsTotal.WithLabelValues(shard, status).Inc()
+		metrics.TransactionShardJobDuration.WithLabelValues(shard).Observe(time.Since(startTime).Seconds())
+
 		if job.ResultChan != nil {
 			job.ResultChan <- result
 		}
 
-		utils.Logger.Info("Worker " + string(rune(id+'0')) + " processed job in " + time.Since(startTime).String())
+		utils.Logger.Info("Worker " + shard + " processed job in " + time.Since(startTime).String())
+	}
+}
+
+// process runs the job with its context, cancelled as well when the pool
+// is stopped.
+func (p *TransactionWorkerPool) process(job TransactionJob) TransactionResult {
+	if job.Ctx == nil {
+		job.Ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(job.Ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+
+	job.Ctx = ctx
+	return p.processor(job)
+}
+
+// shardOf maps an account to the worker that owns it.
+func (p *TransactionWorkerPool) shardOf(userID uuid.UUID) int {
+	hash := fnv.New32a()
+	hash.Write(userID[:])
+	return int(hash.Sum32() % uint32(len(p.shards)))
+}
+
+// enqueue places the job on the shard of every account it touches, waiting
+// for room until ctx ends. The read lock keeps Stop from closing the shards
+// while a job is being placed.
+func (p *TransactionWorkerPool) enqueue(ctx context.Context, job TransactionJob) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return ErrPoolStopped
+	}
+
+	var shards []int
+	for _, userID := range []*uuid.UUID{job.FromUserID, job.ToUserID} {
+		if userID == nil {
+			continue
+		}
+		shard := p.shardOf(*userID)
+		if len(shards) == 0 || shards[0] != shard {
+			shards = append(shards, shard)
+		}
+	}
+
+	if len(shards) == 1 {
+		if err := p.send(ctx, shards[0], shardJob{job: job}); err != nil {
+			return err
+		}
+		p.admitted()
+		return nil
+	}
+
+	primary, secondary := shards[0], shards[1]
+	if secondary < primary {
+		primary, secondary = secondary, primary
+	}
+
+	barrier := &shardBarrier{
+		arrived: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	p.crossShardMu.Lock()
+	defer p.crossShardMu.Unlock()
+
+	if err := p.send(ctx, primary, shardJob{job: job, barrier: barrier, primary: true}); err != nil {
+		return err
+	}
+
+	if err := p.send(ctx, secondary, shardJob{job: job, barrier: barrier}); err != nil {
+		barrier.cancelled = true
+		close(barrier.arrived)
+		return err
+	}
+
+	p.admitted()
+	return nil
+}
+
+func (p *TransactionWorkerPool) send(ctx context.Context, shard int, item shardJob) error {
+	item.enqueuedAt = time.Now()
+
+	select {
+	case p.shards[shard] <- item:
+		return nil
+	default:
 	}
+
+	select {
+	case p.shards[shard] <- item:
+		return nil
+	case <-ctx.Done():
+		metrics.TransactionQueueRejectedTotal.Inc()
+		return ErrQueueFull
+	}
+}
+
+func (p *TransactionWorkerPool) admitted() {
+	metrics.TransactionQueueDepth.Set(float64(atomic.AddInt64(&p.pending, 1)))
 }
 
 func (p *TransactionWorkerPool) updateAmountStats(job TransactionJob) {
@@ -106,14 +303,21 @@ func (p *TransactionWorkerPool) updateAmountStats(job TransactionJob) {
 	}
 }
 
-func (p *TransactionWorkerPool) Submit(job TransactionJob) {
-	p.jobQueue <- job
+// Submit queues the job without waiting for its result. It returns
+// ErrQueueFull when the job's shards have no room before ctx ends and
+// ErrPoolStopped once the pool is stopping.
+func (p *TransactionWorkerPool) Submit(ctx context.Context, job TransactionJob) error {
+	return p.enqueue(ctx, job)
 }
 
-func (p *TransactionWorkerPool) SubmitAndWait(job TransactionJob) TransactionResult {
+// SubmitAndWait queues the job and waits for its result. Once queued the job
+// runs to completion, ctx only bounds the wait for room in the queue.
+func (p *TransactionWorkerPool) SubmitAndWait(ctx context.Context, job TransactionJob) (TransactionResult, error) {
 	job.ResultChan = make(chan TransactionResult, 1)
-	p.jobQueue <- job
-	return <-job.ResultChan
+	if err := p.enqueue(ctx, job); err != nil {
+		return TransactionResult{}, err
+	}
+	return <-job.ResultChan, nil
 }
 
 func (p *TransactionWorkerPool) GetStats() TransactionStats {
@@ -127,11 +331,25 @@ func (p *TransactionWorkerPool) GetStats() TransactionStats {
 	}
 }
 
+// QueueLength counts jobs submitted but not processed yet, a cross-shard
+// transfer counts once.
 func (p *TransactionWorkerPool) QueueLength() int {
-	return len(p.jobQueue)
+	return int(atomic.LoadInt64(&p.pending))
+}
+
+// ShardQueueLengths reports the jobs waiting on each shard.
+func (p *TransactionWorkerPool) ShardQueueLengths() []int {
+	lengths := make([]int, len(p.shards))
+	for i, shard := range p.shards {
+		lengths[i] = len(shard)
+	}
+	return lengths
 }
 
-func (p *TransactionWorkerPool) Stop() {
+// Stop stops accepting work and lets the workers drain their queues. Jobs
+// still queued or running after timeout have their context cancelled, so
+// they end with context.Canceled instead of touching the database.
+func (p *TransactionWorkerPool) Stop(timeout time.Duration) {
 	p.mu.Lock()
 	if !p.running {
 		p.mu.Unlock()
@@ -140,7 +358,23 @@ func (p *TransactionWorkerPool) Stop() {
 	p.running = false
 	p.mu.Unlock()
 
-	close(p.jobQueue)
-	p.wg.Wait()
+	for _, shard := range p.shards {
+		close(shard)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		utils.Logger.Warn("Transaction worker pool did not drain in " + timeout.String() + ", cancelling jobs in flight")
+		p.cancel()
+		<-done
+	}
+	p.cancel()
 	utils.Logger.Info("Transaction worker pool stopped")
 }
\ No newline at end of file
@@ -19,6 +19,7 @@ func Setup() {
 	_ = tracing.Init(serviceName, serviceVersion)
 
 	config := Config{}
+	config.JwtConfig()
 	config.GormDatabase()
 	config.RedisConfig()
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+package configs
+
+import (
+	"backend-path/app/tokens"
+	"backend-path/utils"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var JwtKeys *tokens.KeyStore
+
+// JwtConfig loads the token signing keys.
+//
+// With JWT_KEYS_DIR set every <kid>.pem file in that directory is loaded:
+// private RSA keys sign with RS256, private EC keys with ES256/384/512, and
+// public keys are verify-only. JWT_ACTIVE_KID picks the key used to sign new
+// tokens. Rotation is done in three steps:
+//  1. drop the new private key into JWT_KEYS_DIR and restart, it is now
+//     published in /.well-known/jwks.json and accepted for verification
+//  2. point JWT_ACTIVE_KID at the new key so new tokens are signed with it
+//  3. once JWT_EXPIRES has passed, replace the old key with its public half
+//     (or remove it) to retire it
+//
+// Without JWT_KEYS_DIR tokens are signed with HS256 using JWT_SECRET.
+//
+// Tokens issued before keys were named carry no kid and are verified with
+// JWT_SECRET until they expire, so signing in again is not forced on
+// upgrade. With JWT_KEYS_DIR keep JWT_SECRET set for one JWT_EXPIRES after
+// the upgrade, then remove it.
+func (c *Config) JwtConfig() {
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "backend-path"
+	}
+
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = "backend-path-api"
+	}
+
+	store := tokens.NewKeyStore(issuer, audience)
+
+	secret := os.Getenv("JWT_SECRET")
+	if secret != "" {
+		store.SetLegacy(tokens.NewHMACKey("legacy", []byte(secret)))
+	}
+
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		if secret == "" {
+			log.Fatal("you should add JWT_SECRET or JWT_KEYS_DIR variables")
+		}
+
+		store.Add(tokens.NewHMACKey("hs256", []byte(secret)))
+		store.SetActive("hs256")
+		JwtKeys = store
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(keysDir, "*.pem"))
+	if err != nil || len(files) == 0 {
+		log.Fatalf("no signing keys found in %s", keysDir)
+	}
+
+	signingKeys := []string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("Error reading signing key %s: %s", file, err.Error())
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		key, err := tokens.ParsePEMKey(kid, data)
+		if err != nil {
+			log.Fatalf("Error parsing signing key %s: %s", file, err.Error())
+		}
+
+		store.Add(key)
+		if key.CanSign() {
+			signingKeys = append(signingKeys, kid)
+		}
+		utils.Logger.Info("Loaded JWT key " + kid + " (" + key.Method.Alg() + ")")
+	}
+
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+	if activeKid == "" && len(signingKeys) == 1 {
+		activeKid = signingKeys[0]
+	}
+
+	if err := store.SetActive(activeKid); err != nil {
+		log.Fatalf("Error activating signing key %q: %s", activeKid, err.Error())
+	}
+
+	JwtKeys = store
+}
@@ -2,6 +2,7 @@ package constants
 
 const (
 	CacheUserList   = "USER_LIST"
+	CacheUserListVersion = "USER_LIST_VERSION"
 	CacheUserDetail = "USER_DETAIL"
 	CacheBalanceCurrent = "BALANCE_CURRENT"
 	CacheBalanceHistorical = "BALANCE_HISTORICAL"
@@ -9,5 +10,10 @@ const (
 	CacheBalanceAtTime = "BALANCE_AT_TIME"
 	CacheTransactionDetail = "TX_DETAIL"
 	CacheTransactionHistory = "TX_HISTORY"
-	CacheTransactionStats = "TX_STATS"
+	CacheLoginAttempts = "LOGIN_ATTEMPTS"
+	CacheLoginLock = "LOGIN_LOCK"
+	CacheLoginLockouts = "LOGIN_LOCKOUTS"
+	CacheRolePermissions = "ROLE_PERMISSIONS"
+	CacheSession = "SESSION"
+	CacheAccountStatus = "ACCOUNT_STATUS"
 )
\ No newline at end of file
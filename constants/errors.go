@@ -5,4 +5,11 @@ import "errors"
 var (
 	ErrInvalidAuth  = errors.New("username or password is wrong")
 	ErrEmailExist   = errors.New("email address already exist")
+	ErrAccountLocked = errors.New("too many failed login attempts, try again later")
+	ErrAccountClosed = errors.New("account is closed")
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrRecipientRestricted = errors.New("recipient account cannot receive funds")
+	ErrDailyLimitExceeded = errors.New("amount exceeds the daily limit of your kyc tier")
 )
\ No newline at end of file
@@ -3,4 +3,5 @@ package constants
 const (
 	RequestIDHeader = "X-Request-ID"
 	RequestIDLocal = "requestid"
+	ApiKeyHeader = "X-API-Key"
 )
\ No newline at end of file
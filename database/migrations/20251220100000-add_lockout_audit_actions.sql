@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 13);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 11;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 11);
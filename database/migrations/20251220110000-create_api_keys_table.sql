@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE api_keys (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    scopes varchar(255) NOT NULL,
+    last_used_at timestamp with time zone,
+    expires_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now(),
+
+    CONSTRAINT api_keys_prefix_unique UNIQUE (prefix)
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id);
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_entity_type_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 5);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE entity_type > 4;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_entity_type_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 4);
+
+DROP TABLE api_keys;
@@ -0,0 +1,58 @@
+-- +migrate Up
+CREATE TABLE roles (
+    id smallserial PRIMARY KEY,
+    name varchar(50) NOT NULL,
+    description varchar(255),
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now(),
+
+    CONSTRAINT roles_name_unique UNIQUE (name)
+);
+
+INSERT INTO roles (id, name, description) VALUES
+    (1, 'user', 'Regular account holder'),
+    (2, 'admin', 'Full administrative access'),
+    (3, 'mod', 'Moderator with read access to users');
+
+SELECT setval('roles_id_seq', 3);
+
+CREATE TABLE permissions (
+    id serial PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    description varchar(255),
+
+    CONSTRAINT permissions_name_unique UNIQUE (name)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'List and view users'),
+    ('users:update', 'Update users and their roles'),
+    ('users:delete', 'Delete users'),
+    ('users:unlock', 'Unlock accounts locked after failed logins'),
+    ('roles:manage', 'Create, update and delete roles'),
+    ('transactions:stats', 'View transaction statistics');
+
+CREATE TABLE role_permissions (
+    role_id smallint NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id integer NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 2, id FROM permissions;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 3, id FROM permissions WHERE name IN ('users:read');
+
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_fk FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT;
+
+-- +migrate Down
+ALTER TABLE users DROP CONSTRAINT users_role_fk;
+UPDATE users SET role_id = 1 WHERE role_id > 3;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role_id BETWEEN 1 AND 3);
+
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
@@ -0,0 +1,28 @@
+-- +migrate Up
+ALTER TABLE users
+    ADD COLUMN status smallint NOT NULL DEFAULT 1,
+    ADD COLUMN pending_email varchar(100),
+    ADD COLUMN email_verification_hash varchar(64),
+    ADD COLUMN email_verification_expires_at timestamp with time zone,
+    ADD COLUMN closed_at timestamp with time zone,
+    ADD CONSTRAINT users_status_check CHECK (status BETWEEN 1 AND 2);
+
+CREATE INDEX idx_users_status ON users(status);
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 14);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 13;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 13);
+
+DROP INDEX idx_users_status;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_status_check,
+    DROP COLUMN closed_at,
+    DROP COLUMN email_verification_expires_at,
+    DROP COLUMN email_verification_hash,
+    DROP COLUMN pending_email,
+    DROP COLUMN status;
@@ -0,0 +1,40 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN deleted_at timestamp with time zone;
+
+ALTER TABLE users DROP CONSTRAINT users_username_unique;
+ALTER TABLE users DROP CONSTRAINT users_email_unique;
+CREATE UNIQUE INDEX users_username_unique ON users(username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_email_unique ON users(email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- money and history must never disappear with a user row
+ALTER TABLE balances DROP CONSTRAINT balances_user_id_fkey;
+ALTER TABLE balances ADD CONSTRAINT balances_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE transactions DROP CONSTRAINT transactions_from_user_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_from_user_id_fkey FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE transactions DROP CONSTRAINT transactions_to_user_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_to_user_id_fkey FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 16);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 14;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 14);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_to_user_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_to_user_id_fkey FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE transactions DROP CONSTRAINT transactions_from_user_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_from_user_id_fkey FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE balances DROP CONSTRAINT balances_user_id_fkey;
+ALTER TABLE balances ADD CONSTRAINT balances_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_users_deleted_at;
+DROP INDEX users_email_unique;
+DROP INDEX users_username_unique;
+ALTER TABLE users ADD CONSTRAINT users_username_unique UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_unique UNIQUE (email);
+
+ALTER TABLE users DROP COLUMN deleted_at;
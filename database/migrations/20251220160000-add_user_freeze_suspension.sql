@@ -0,0 +1,34 @@
+-- +migrate Up
+ALTER TABLE users
+    ADD COLUMN status_reason varchar(255),
+    ADD COLUMN status_expires_at timestamp with time zone,
+    ADD COLUMN status_changed_by uuid;
+
+ALTER TABLE users DROP CONSTRAINT users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status BETWEEN 1 AND 4);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:freeze', 'Freeze, suspend and reactivate accounts');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('admin', 'mod') AND p.name = 'users:freeze';
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 19);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 16;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 16);
+
+DELETE FROM permissions WHERE name = 'users:freeze';
+
+UPDATE users SET status = 1 WHERE status > 2;
+ALTER TABLE users DROP CONSTRAINT users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status BETWEEN 1 AND 2);
+
+ALTER TABLE users
+    DROP COLUMN status_changed_by,
+    DROP COLUMN status_expires_at,
+    DROP COLUMN status_reason;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX users_username_trgm_idx ON users USING gin (username gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX users_email_trgm_idx ON users USING gin (email gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX users_created_at_idx ON users (created_at) WHERE deleted_at IS NULL;
+CREATE INDEX users_role_status_idx ON users (role_id, status) WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS users_role_status_idx;
+DROP INDEX IF EXISTS users_created_at_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_username_trgm_idx;
@@ -0,0 +1,38 @@
+-- +migrate Up
+CREATE TABLE data_exports (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status smallint NOT NULL DEFAULT 1 CHECK (status BETWEEN 1 AND 3),
+    blob_key varchar(255),
+    size bigint NOT NULL DEFAULT 0,
+    error varchar(255),
+    completed_at timestamp with time zone,
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+CREATE INDEX data_exports_expires_at_idx ON data_exports (expires_at);
+
+ALTER TABLE users ADD COLUMN erased_at timestamp with time zone;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:erase', 'Erase personal data of users');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'users:erase';
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 21);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 19;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 19);
+
+DELETE FROM permissions WHERE name = 'users:erase';
+
+ALTER TABLE users DROP COLUMN erased_at;
+
+DROP TABLE data_exports;
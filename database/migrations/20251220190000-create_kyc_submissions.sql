@@ -0,0 +1,53 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN kyc_tier smallint NOT NULL DEFAULT 1 CHECK (kyc_tier BETWEEN 1 AND 3);
+
+-- accounts that already move money keep doing so, new accounts start at basic
+UPDATE users SET kyc_tier = 2;
+
+CREATE TABLE kyc_submissions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    requested_tier smallint NOT NULL CHECK (requested_tier BETWEEN 2 AND 3),
+    status smallint NOT NULL DEFAULT 1 CHECK (status BETWEEN 1 AND 3),
+    full_name varchar(150) NOT NULL,
+    date_of_birth date NOT NULL,
+    country char(2) NOT NULL,
+    document_type varchar(30) NOT NULL,
+    document_number varchar(50) NOT NULL,
+    document_blob_key varchar(255) NOT NULL,
+    document_content_type varchar(100) NOT NULL,
+    reviewer_id uuid REFERENCES users(id),
+    review_note varchar(500),
+    reviewed_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX kyc_submissions_user_id_idx ON kyc_submissions (user_id);
+CREATE INDEX kyc_submissions_status_created_at_idx ON kyc_submissions (status, created_at);
+CREATE UNIQUE INDEX kyc_submissions_one_pending_idx ON kyc_submissions (user_id) WHERE status = 1;
+
+INSERT INTO permissions (name, description) VALUES
+    ('kyc:review', 'Review kyc submissions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('admin', 'mod') AND p.name = 'kyc:review';
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 24);
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_entity_type_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 6);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 21 OR entity_type > 5;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_entity_type_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 5);
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 21);
+
+DELETE FROM permissions WHERE name = 'kyc:review';
+
+DROP TABLE kyc_submissions;
+
+ALTER TABLE users DROP COLUMN kyc_tier;
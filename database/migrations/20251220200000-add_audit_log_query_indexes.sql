@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE INDEX audit_logs_created_at_id_idx ON audit_logs (created_at, id);
+CREATE INDEX audit_logs_entity_id_created_at_idx ON audit_logs (entity_id, created_at, id);
+CREATE INDEX audit_logs_details_idx ON audit_logs USING gin (details jsonb_path_ops);
+CREATE INDEX audit_logs_actor_id_idx ON audit_logs ((details->>'actor_id'));
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Query and export audit logs');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'audit:read';
+
+-- +migrate Down
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP INDEX IF EXISTS audit_logs_actor_id_idx;
+DROP INDEX IF EXISTS audit_logs_details_idx;
+DROP INDEX IF EXISTS audit_logs_entity_id_created_at_idx;
+DROP INDEX IF EXISTS audit_logs_created_at_id_idx;
@@ -0,0 +1,47 @@
+-- +migrate Up
+ALTER TABLE audit_logs
+    ADD COLUMN seq bigint,
+    ADD COLUMN prev_hash varchar(64),
+    ADD COLUMN content_hash varchar(64),
+    ADD COLUMN hash varchar(64),
+    ADD COLUMN redacted_at timestamp with time zone;
+
+-- existing entries get their chain position here, the application computes
+-- their hashes when the chain is next extended or anchored
+UPDATE audit_logs l SET seq = o.seq
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY entity_type, entity_id ORDER BY created_at, id) AS seq
+    FROM audit_logs
+) o
+WHERE l.id = o.id;
+
+UPDATE audit_logs SET redacted_at = now() WHERE details ? 'pseudonymized';
+
+ALTER TABLE audit_logs ALTER COLUMN seq SET NOT NULL;
+
+CREATE UNIQUE INDEX audit_logs_chain_idx ON audit_logs (entity_type, entity_id, seq);
+CREATE INDEX audit_logs_unsealed_idx ON audit_logs (entity_type, entity_id) WHERE hash IS NULL;
+
+CREATE TABLE audit_chain_anchors (
+    id bigserial PRIMARY KEY,
+    entity_type smallint NOT NULL,
+    entity_id uuid NOT NULL,
+    seq bigint NOT NULL,
+    hash varchar(64) NOT NULL,
+    anchored_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_chain_anchors_chain_idx ON audit_chain_anchors (entity_type, entity_id, seq DESC);
+
+-- +migrate Down
+DROP TABLE audit_chain_anchors;
+
+DROP INDEX audit_logs_unsealed_idx;
+DROP INDEX audit_logs_chain_idx;
+
+ALTER TABLE audit_logs
+    DROP COLUMN seq,
+    DROP COLUMN prev_hash,
+    DROP COLUMN content_hash,
+    DROP COLUMN hash,
+    DROP COLUMN redacted_at;
@@ -0,0 +1,30 @@
+-- +migrate Up
+ALTER TABLE audit_logs
+    ADD COLUMN actor_user_id uuid,
+    ADD COLUMN actor_role varchar(50),
+    ADD COLUMN request_id varchar(100),
+    ADD COLUMN trace_id varchar(32),
+    ADD COLUMN ip varchar(45),
+    ADD COLUMN changes jsonb;
+
+CREATE INDEX audit_logs_actor_user_id_idx ON audit_logs (actor_user_id) WHERE actor_user_id IS NOT NULL;
+CREATE INDEX audit_logs_request_id_idx ON audit_logs (request_id) WHERE request_id IS NOT NULL;
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 25);
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action > 24;
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 24);
+
+DROP INDEX audit_logs_request_id_idx;
+DROP INDEX audit_logs_actor_user_id_idx;
+
+ALTER TABLE audit_logs
+    DROP COLUMN actor_user_id,
+    DROP COLUMN actor_role,
+    DROP COLUMN request_id,
+    DROP COLUMN trace_id,
+    DROP COLUMN ip,
+    DROP COLUMN changes;
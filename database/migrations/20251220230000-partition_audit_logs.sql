@@ -0,0 +1,141 @@
+-- +migrate Up
+ALTER TABLE audit_logs RENAME TO audit_logs_legacy;
+ALTER INDEX audit_logs_pkey RENAME TO audit_logs_legacy_pkey;
+
+CREATE TABLE audit_logs (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    entity_type smallint NOT NULL,
+    entity_id uuid NOT NULL,
+    action smallint NOT NULL,
+    details jsonb,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    seq bigint NOT NULL,
+    prev_hash varchar(64),
+    content_hash varchar(64),
+    hash varchar(64),
+    redacted_at timestamp with time zone,
+    actor_user_id uuid,
+    actor_role varchar(50),
+    request_id varchar(100),
+    trace_id varchar(32),
+    ip varchar(45),
+    changes jsonb,
+
+    PRIMARY KEY (id, created_at),
+    CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 6),
+    CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 25)
+) PARTITION BY RANGE (created_at);
+
+-- monthly partitions in UTC from the oldest entry up to three months ahead,
+-- the application creates later months as time goes on
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    month timestamp := date_trunc('month', COALESCE((SELECT min(created_at) FROM audit_logs_legacy), now()) AT TIME ZONE 'UTC');
+BEGIN
+    WHILE month <= date_trunc('month', now() AT TIME ZONE 'UTC') + interval '3 months' LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF audit_logs FOR VALUES FROM (%L) TO (%L)',
+            'audit_logs_' || to_char(month, 'YYYY_MM'),
+            to_char(month, 'YYYY-MM-DD') || ' 00:00:00+00',
+            to_char(month + interval '1 month', 'YYYY-MM-DD') || ' 00:00:00+00'
+        );
+        month := month + interval '1 month';
+    END LOOP;
+END
+$$;
+-- +migrate StatementEnd
+
+CREATE TABLE audit_logs_default PARTITION OF audit_logs DEFAULT;
+
+INSERT INTO audit_logs (id, entity_type, entity_id, action, details, created_at, seq, prev_hash, content_hash, hash,
+    redacted_at, actor_user_id, actor_role, request_id, trace_id, ip, changes)
+SELECT id, entity_type, entity_id, action, details, COALESCE(created_at, now()), seq, prev_hash, content_hash, hash,
+    redacted_at, actor_user_id, actor_role, request_id, trace_id, ip, changes
+FROM audit_logs_legacy;
+
+DROP TABLE audit_logs_legacy;
+
+CREATE INDEX idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX idx_audit_logs_created ON audit_logs (created_at DESC);
+CREATE INDEX audit_logs_created_at_id_idx ON audit_logs (created_at, id);
+CREATE INDEX audit_logs_entity_id_created_at_idx ON audit_logs (entity_id, created_at, id);
+CREATE INDEX audit_logs_details_idx ON audit_logs USING gin (details jsonb_path_ops);
+CREATE INDEX audit_logs_actor_id_idx ON audit_logs ((details->>'actor_id'));
+CREATE INDEX audit_logs_actor_user_id_idx ON audit_logs (actor_user_id) WHERE actor_user_id IS NOT NULL;
+CREATE INDEX audit_logs_request_id_idx ON audit_logs (request_id) WHERE request_id IS NOT NULL;
+CREATE INDEX audit_logs_unsealed_idx ON audit_logs (entity_type, entity_id) WHERE hash IS NULL;
+-- unique indexes on a partitioned table must contain created_at, chain
+-- writers are serialized by an advisory lock instead
+CREATE INDEX audit_logs_chain_idx ON audit_logs (entity_type, entity_id, seq);
+
+-- the latest archived entry of every chain, verification continues from it
+CREATE TABLE audit_chain_archive_heads (
+    entity_type smallint NOT NULL,
+    entity_id uuid NOT NULL,
+    seq bigint NOT NULL,
+    hash varchar(64) NOT NULL,
+    archived_at timestamp with time zone NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE TABLE audit_log_archives (
+    id bigserial PRIMARY KEY,
+    partition_name varchar(63) NOT NULL,
+    entity_type smallint,
+    blob_key varchar(255) NOT NULL,
+    row_count bigint NOT NULL,
+    sha256 varchar(64) NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_log_archives_partition_idx ON audit_log_archives (partition_name);
+
+-- +migrate Down
+DROP TABLE audit_log_archives;
+DROP TABLE audit_chain_archive_heads;
+
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+
+CREATE TABLE audit_logs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity_type smallint NOT NULL,
+    entity_id uuid NOT NULL,
+    action smallint NOT NULL,
+    details jsonb,
+    created_at timestamp with time zone DEFAULT now(),
+    seq bigint NOT NULL,
+    prev_hash varchar(64),
+    content_hash varchar(64),
+    hash varchar(64),
+    redacted_at timestamp with time zone,
+    actor_user_id uuid,
+    actor_role varchar(50),
+    request_id varchar(100),
+    trace_id varchar(32),
+    ip varchar(45),
+    changes jsonb,
+
+    CONSTRAINT audit_logs_entity_type_check CHECK (entity_type BETWEEN 1 AND 6),
+    CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 25)
+);
+
+INSERT INTO audit_logs SELECT id, entity_type, entity_id, action, details, created_at, seq, prev_hash, content_hash, hash,
+    redacted_at, actor_user_id, actor_role, request_id, trace_id, ip, changes
+FROM audit_logs_partitioned;
+
+DROP TABLE audit_logs_partitioned;
+
+CREATE INDEX idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX idx_audit_logs_created ON audit_logs (created_at DESC);
+CREATE INDEX audit_logs_created_at_id_idx ON audit_logs (created_at, id);
+CREATE INDEX audit_logs_entity_id_created_at_idx ON audit_logs (entity_id, created_at, id);
+CREATE INDEX audit_logs_details_idx ON audit_logs USING gin (details jsonb_path_ops);
+CREATE INDEX audit_logs_actor_id_idx ON audit_logs ((details->>'actor_id'));
+CREATE INDEX audit_logs_actor_user_id_idx ON audit_logs (actor_user_id) WHERE actor_user_id IS NOT NULL;
+CREATE INDEX audit_logs_request_id_idx ON audit_logs (request_id) WHERE request_id IS NOT NULL;
+CREATE INDEX audit_logs_unsealed_idx ON audit_logs (entity_type, entity_id) WHERE hash IS NULL;
+CREATE UNIQUE INDEX audit_logs_chain_idx ON audit_logs (entity_type, entity_id, seq);
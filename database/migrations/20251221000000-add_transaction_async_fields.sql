@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE transactions
+    ADD COLUMN callback_url text,
+    ADD COLUMN failure_reason text;
+
+-- +migrate Down
+ALTER TABLE transactions
+    DROP COLUMN callback_url,
+    DROP COLUMN failure_reason;
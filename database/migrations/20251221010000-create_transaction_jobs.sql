@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE transaction_jobs (
+    id uuid PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+    type smallint NOT NULL,
+    from_user_id uuid,
+    to_user_id uuid,
+    amount decimal(15,2) NOT NULL,
+    audit_actor_user_id uuid,
+    audit_actor_role varchar(50),
+    audit_request_id varchar(100),
+    audit_trace_id varchar(32),
+    audit_ip varchar(45),
+    status smallint NOT NULL DEFAULT 1 CHECK (status BETWEEN 1 AND 2),
+    attempts integer NOT NULL DEFAULT 0,
+    locked_by varchar(255),
+    locked_until timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_jobs_claim ON transaction_jobs(status, created_at);
+
+-- +migrate Down
+DROP TABLE transaction_jobs;
@@ -0,0 +1,38 @@
+-- +migrate Up
+CREATE TABLE transaction_dead_letters (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id uuid NOT NULL,
+    type smallint NOT NULL,
+    from_user_id uuid,
+    to_user_id uuid,
+    amount decimal(15,2) NOT NULL,
+    audit_actor_user_id uuid,
+    audit_actor_role varchar(50),
+    audit_request_id varchar(100),
+    audit_trace_id varchar(32),
+    audit_ip varchar(45),
+    async boolean NOT NULL DEFAULT false,
+    error text NOT NULL,
+    attempts integer NOT NULL,
+    status smallint NOT NULL DEFAULT 1 CHECK (status BETWEEN 1 AND 3),
+    replayed_transaction_id uuid REFERENCES transactions(id) ON DELETE SET NULL,
+    resolved_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at timestamp with time zone,
+    submitted_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_dead_letters_job ON transaction_dead_letters(job_id);
+CREATE INDEX idx_transaction_dead_letters_status ON transaction_dead_letters(status, created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('transactions:replay', 'Inspect, replay and discard failed transaction jobs');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'transactions:replay';
+
+-- +migrate Down
+DELETE FROM permissions WHERE name = 'transactions:replay';
+
+DROP TABLE transaction_dead_letters;
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE transaction_stats_hourly (
+    bucket timestamp with time zone NOT NULL,
+    type smallint NOT NULL,
+    status smallint NOT NULL,
+    slot smallint NOT NULL DEFAULT 0,
+    count bigint NOT NULL DEFAULT 0,
+    amount decimal(20,2) NOT NULL DEFAULT 0,
+    updated_at timestamp with time zone DEFAULT now(),
+    PRIMARY KEY (bucket, type, status, slot)
+);
+
+INSERT INTO transaction_stats_hourly (bucket, type, status, count, amount)
+SELECT date_trunc('hour', created_at), type, status, count(*), sum(amount)
+FROM transactions
+WHERE status IN (2, 3)
+GROUP BY date_trunc('hour', created_at), type, status;
+
+-- +migrate Down
+DROP TABLE transaction_stats_hourly;
@@ -0,0 +1,38 @@
+-- +migrate Up
+ALTER TABLE audit_logs
+    ADD COLUMN pii_hash varchar(64),
+    ADD COLUMN redacted_by uuid;
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 26);
+
+CREATE INDEX audit_logs_redacted_by_idx ON audit_logs (redacted_by) WHERE redacted_by IS NOT NULL;
+
+-- entries erased so far are listed in one redaction entry on a chain of its
+-- own, the application seals it when the chains are next anchored
+INSERT INTO audit_logs (id, entity_type, entity_id, action, details, created_at, seq)
+SELECT gen_random_uuid(), 1, '00000000-0000-0000-0000-000000000000', 26,
+    jsonb_build_object('entries', jsonb_agg(id ORDER BY id)), now(),
+    COALESCE((SELECT max(seq) FROM audit_logs
+        WHERE entity_type = 1 AND entity_id = '00000000-0000-0000-0000-000000000000'), 0) + 1
+FROM audit_logs
+WHERE redacted_at IS NOT NULL
+HAVING count(*) > 0;
+
+UPDATE audit_logs SET redacted_by = (
+    SELECT id FROM audit_logs
+    WHERE action = 26 AND entity_type = 1 AND entity_id = '00000000-0000-0000-0000-000000000000'
+)
+WHERE redacted_at IS NOT NULL;
+
+-- +migrate Down
+DELETE FROM audit_logs WHERE action = 26;
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check CHECK (action BETWEEN 1 AND 25);
+
+DROP INDEX audit_logs_redacted_by_idx;
+
+ALTER TABLE audit_logs
+    DROP COLUMN pii_hash,
+    DROP COLUMN redacted_by;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE kyc_submissions
+    ALTER COLUMN date_of_birth DROP NOT NULL,
+    ADD COLUMN erased_at timestamp with time zone;
+
+-- +migrate Down
+ALTER TABLE kyc_submissions DROP COLUMN erased_at;
+
+UPDATE kyc_submissions SET date_of_birth = '1900-01-01' WHERE date_of_birth IS NULL;
+
+ALTER TABLE kyc_submissions ALTER COLUMN date_of_birth SET NOT NULL;
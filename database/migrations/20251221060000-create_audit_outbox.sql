@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE audit_outbox (
+    id uuid PRIMARY KEY,
+    event jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX idx_audit_outbox_created_at ON audit_outbox(created_at);
+
+-- +migrate Down
+DROP TABLE audit_outbox;
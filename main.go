@@ -1,9 +1,13 @@
 package main
 
 import (
+	"backend-path/app/audit"
+	"backend-path/app/lifecycle"
 	"backend-path/app/middlewares"
 	"backend-path/app/repository"
 	"backend-path/app/server"
+	"backend-path/app/services"
+	"backend-path/app/workers"
 	"backend-path/configs"
 	"backend-path/database/seeders"
 	"backend-path/routes"
@@ -13,6 +17,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
@@ -36,8 +41,10 @@ func main() {
 	routes.Setup(app)
 	argsListener()
 
+	startBackgroundWorkers()
+
 	
-	srv := server.New(app, configs.DB)
+	srv := server.New(app, configs.DB, configs.RedisStorage)
 	if err := srv.Start(os.Getenv("APP_PORT")); err != nil {
 		log.Fatal(err)
 	}
@@ -49,14 +56,91 @@ func fiberConfig() *fiber.App {
 		maxBody = 4
 	}
 
+	// bodies over the limit are streamed instead of read, so
+	// middlewares.RequestBodyLimit refuses them before they are read and
+	// lets registered uploads through up to their own limit
 	app := fiber.New(fiber.Config{
-		ReadBufferSize: maxBody * 1024,
-		BodyLimit:      maxBody * 1024,
+		ReadBufferSize:               maxBody * 1024,
+		BodyLimit:                    maxBody * 1024,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
+	middlewares.AllowUpload(fiber.MethodPost, "/api/v1/users/me/kyc", services.KycDocumentMaxBytes()+64*1024)
+
 	return app
 }
 
+func startBackgroundWorkers() {
+	purgeInterval, _ := strconv.Atoi(os.Getenv("USER_PURGE_INTERVAL_MINUTES"))
+	if purgeInterval == 0 {
+		purgeInterval = 60
+	}
+
+	userPurgeWorker := workers.NewExclusiveWorker(
+		"user-purge",
+		time.Duration(purgeInterval)*time.Minute,
+		services.NewUserService().PurgeDeleted,
+	)
+	startPeriodicWorker(userPurgeWorker)
+
+	dataExportCleanupWorker := workers.NewPeriodicWorker(
+		"data-export-cleanup",
+		time.Hour,
+		services.NewDataPrivacyService().CleanupExports,
+	)
+	startPeriodicWorker(dataExportCleanupWorker)
+
+	anchorInterval, _ := strconv.Atoi(os.Getenv("AUDIT_ANCHOR_INTERVAL_MINUTES"))
+	if anchorInterval == 0 {
+		anchorInterval = 60
+	}
+
+	auditAnchorWorker := workers.NewExclusiveWorker(
+		"audit-chain-anchor",
+		time.Duration(anchorInterval)*time.Minute,
+		audit.AnchorChains,
+	)
+	startPeriodicWorker(auditAnchorWorker)
+
+	if err := audit.EnsurePartitions(); err != nil {
+		utils.Logger.Error("❌ AUDIT PARTITIONS ERROR: " + err.Error())
+	}
+
+	archiveInterval, _ := strconv.Atoi(os.Getenv("AUDIT_ARCHIVE_INTERVAL_HOURS"))
+	if archiveInterval == 0 {
+		archiveInterval = 24
+	}
+
+	auditArchiveWorker := workers.NewExclusiveWorker(
+		"audit-partition-maintenance",
+		time.Duration(archiveInterval)*time.Hour,
+		audit.MaintainPartitions,
+	)
+	startPeriodicWorker(auditArchiveWorker)
+
+	if err := services.UpdateDeadLetterBacklog(); err != nil {
+		utils.Logger.Error("❌ DEAD LETTER BACKLOG ERROR: " + err.Error())
+	}
+
+	deadLetterBacklogWorker := workers.NewPeriodicWorker(
+		"transaction-dead-letter-backlog",
+		time.Minute,
+		services.UpdateDeadLetterBacklog,
+	)
+	startPeriodicWorker(deadLetterBacklogWorker)
+}
+
+// startPeriodicWorker starts the worker and stops it with the other
+// background work on shutdown, before the database closes.
+func startPeriodicWorker(worker *workers.PeriodicWorker) {
+	worker.Start()
+	lifecycle.OnShutdown(lifecycle.PhaseWorkers, worker.Name(), func() error {
+		worker.Stop()
+		return nil
+	})
+}
+
 func argsListener() {
 	homeDir, _ := os.UserHomeDir()
 	sqlMigrate := homeDir + "/go/bin/sql-migrate"
@@ -85,6 +169,23 @@ func argsListener() {
 			}
 		}
 
+		if arg == "--verify-audit-chain" {
+			report, err := audit.VerifyChain(0, nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if !report.Valid {
+				utils.Logger.Error(fmt.Sprintf("❌ audit chain broken at %s %s seq %d: %s",
+					report.Break.EntityType, report.Break.EntityID, report.Break.Seq, report.Break.Reason))
+				os.Exit(1)
+			}
+
+			utils.Logger.Info(fmt.Sprintf("✅ audit chain valid: %d chains, %d entries, %d redacted, %d unsealed",
+				report.Chains, report.Entries, report.Redacted, report.Unsealed))
+			os.Exit(0)
+		}
+
 		if arg == "--seed" {
 			runner := seeders.All(configs.DB)
 			if err := runner.Run(); err != nil {
@@ -17,33 +17,101 @@ func Setup(app *fiber.App) {
     app.Get("/metrics", adaptor.HTTPHandler(
         promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}),
     ))
-	auth := app.Group("/api/v1/auth", middlewares.SetupAuthRateLimiter())
+
+	healthController := controllers.NewHealthController()
+	app.Get("/health/live", healthController.Live)
+	app.Get("/health/ready", healthController.Ready)
+
 	authController := controllers.NewAuthController()
+	app.Get("/.well-known/jwks.json", authController.Jwks)
+
+	auth := app.Group("/api/v1/auth", middlewares.SetupAuthRateLimiter())
 	auth.Post("/register", authController.Register)
 	auth.Post("/login", authController.Login)
-	auth.Post("/refresh", authController.RefreshToken)
+	auth.Post("/refresh", middlewares.DenyApiKey, authController.RefreshToken)
 
-	users := apiRoute.Group("/users")
+	users := apiRoute.Group("/users", middlewares.DenyApiKey)
 	userController := controllers.NewUserController()
-	users.Get("/", middlewares.Role(models.RoleAdmin, models.RoleMod), userController.GetAll)
-	users.Get("/me", middlewares.Role(models.RoleUser, models.RoleAdmin, models.RoleMod), userController.GetMe)
-	users.Get("/:id", middlewares.Role(models.RoleAdmin, models.RoleMod), userController.GetByID)
-	users.Put("/:id", middlewares.Role(models.RoleAdmin), userController.Update)
-	users.Delete("/:id", middlewares.Role(models.RoleAdmin), userController.Delete)
+	users.Get("/", middlewares.RequirePermission(models.PermUsersRead), userController.GetAll)
+	users.Get("/me", userController.GetMe)
+	users.Patch("/me", userController.UpdateMe)
+	users.Post("/me/email/verify", userController.VerifyEmail)
+	users.Post("/me/close", userController.CloseMe)
+
+	apiKeyController := controllers.NewApiKeyController()
+	users.Get("/me/api-keys", apiKeyController.List)
+	users.Post("/me/api-keys", apiKeyController.Create)
+	users.Delete("/me/api-keys/:id", apiKeyController.Revoke)
+
+	sessionController := controllers.NewSessionController()
+	users.Get("/me/sessions", sessionController.List)
+	users.Delete("/me/sessions/:id", sessionController.Revoke)
+
+	dataPrivacyController := controllers.NewDataPrivacyController()
+	users.Post("/me/data-export", dataPrivacyController.RequestExport)
+	users.Get("/me/data-export/:id", dataPrivacyController.GetExport)
+	users.Get("/me/data-export/:id/download", dataPrivacyController.DownloadExport)
+	users.Post("/me/erase", dataPrivacyController.EraseMe)
+
+	kycController := controllers.NewKycController()
+	users.Get("/me/kyc", kycController.GetMine)
+	users.Post("/me/kyc", kycController.Submit)
+
+	users.Get("/:id", middlewares.RequirePermission(models.PermUsersRead), userController.GetByID)
+	users.Put("/:id", middlewares.RequirePermission(models.PermUsersUpdate), userController.Update)
+	users.Delete("/:id", middlewares.RequirePermission(models.PermUsersDelete), userController.Delete)
+	users.Post("/:id/restore", middlewares.RequirePermission(models.PermUsersDelete), userController.Restore)
+	users.Post("/:id/unlock", middlewares.RequirePermission(models.PermUsersUnlock), userController.Unlock)
+	users.Post("/:id/freeze", middlewares.RequirePermission(models.PermUsersFreeze), userController.Freeze)
+	users.Post("/:id/suspend", middlewares.RequirePermission(models.PermUsersFreeze), userController.Suspend)
+	users.Post("/:id/reactivate", middlewares.RequirePermission(models.PermUsersFreeze), userController.Reactivate)
+	users.Post("/:id/erase", middlewares.RequirePermission(models.PermUsersErase), dataPrivacyController.Erase)
+
+	kyc := apiRoute.Group("/kyc", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermKycReview))
+	kyc.Get("/submissions", kycController.GetAll)
+	kyc.Get("/submissions/:id", kycController.GetByID)
+	kyc.Get("/submissions/:id/document", kycController.Document)
+	kyc.Post("/submissions/:id/approve", kycController.Approve)
+	kyc.Post("/submissions/:id/reject", kycController.Reject)
+
+	auditLogs := apiRoute.Group("/audit-logs", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermAuditRead))
+	auditLogController := controllers.NewAuditLogController()
+	auditLogs.Get("/", auditLogController.GetAll)
+	auditLogs.Get("/export", auditLogController.Export)
+	auditLogs.Get("/verify", auditLogController.VerifyChain)
+	auditLogs.Get("/entities/:id/timeline", auditLogController.Timeline)
+
+	roles := apiRoute.Group("/roles", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermRolesManage))
+	roleController := controllers.NewRoleController()
+	roles.Get("/", roleController.GetAll)
+	roles.Post("/", roleController.Create)
+	roles.Get("/:id", roleController.GetByID)
+	roles.Put("/:id", roleController.Update)
+	roles.Delete("/:id", roleController.Delete)
+
+	apiRoute.Get("/permissions", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermRolesManage), roleController.GetPermissions)
 
 	balances := apiRoute.Group("/balances")
 	balanceController := controllers.NewBalanceController()
 
-	balances.Get("/current", balanceController.GetCurrent)
-	balances.Get("/historical", balanceController.GetHistorical)
-	balances.Get("/at-time", balanceController.GetAtTime)
+	balances.Get("/current", middlewares.RequireScope(models.ScopeBalancesRead), balanceController.GetCurrent)
+	balances.Get("/historical", middlewares.RequireScope(models.ScopeBalancesRead), balanceController.GetHistorical)
+	balances.Get("/at-time", middlewares.RequireScope(models.ScopeBalancesRead), balanceController.GetAtTime)
 
 	transactions := apiRoute.Group("/transactions")
 	transactionController := controllers.NewTransactionController()
-	transactions.Post("/credit", transactionController.Credit)
-	transactions.Post("/debit", transactionController.Debit)
-	transactions.Post("/transfer", transactionController.Transfer)
-	transactions.Get("/history", transactionController.GetHistory)
-	transactions.Get("/stats", middlewares.Role(models.RoleAdmin), transactionController.GetStats)
-	transactions.Get("/:id", transactionController.GetByID)
+	transactions.Post("/credit", middlewares.RequireScope(models.ScopeTransactionsWrite), transactionController.Credit)
+	transactions.Post("/debit", middlewares.RequireScope(models.ScopeTransactionsWrite), transactionController.Debit)
+	transactions.Post("/transfer", middlewares.RequireScope(models.ScopeTransactionsWrite), transactionController.Transfer)
+	transactions.Get("/history", middlewares.RequireScope(models.ScopeTransactionsRead), transactionController.GetHistory)
+	transactions.Get("/stats", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermTransactionsStats), transactionController.GetStats)
+
+	deadLetters := transactions.Group("/dead-letters", middlewares.DenyApiKey, middlewares.RequirePermission(models.PermTransactionsReplay))
+	deadLetterController := controllers.NewTransactionDeadLetterController()
+	deadLetters.Get("/", deadLetterController.GetAll)
+	deadLetters.Get("/:id", deadLetterController.GetByID)
+	deadLetters.Post("/:id/replay", deadLetterController.Replay)
+	deadLetters.Post("/:id/discard", deadLetterController.Discard)
+
+	transactions.Get("/:id", middlewares.RequireScope(models.ScopeTransactionsRead), transactionController.GetByID)
 }
\ No newline at end of file
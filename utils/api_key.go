@@ -0,0 +1,40 @@
+package utils
+
+import "strings"
+
+const apiKeyPrefix = "bp"
+
+// GenerateApiKey returns a new key in the form bp_<prefix>_<secret> together
+// with its public prefix. Only the hash of the full key is ever stored. The
+// prefix is unique per key, 64 random bits make a collision unlikely and
+// callers generate a new key when one happens.
+func GenerateApiKey() (string, string, error) {
+	prefix, err := RandomHex(8)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := RandomHex(24)
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKeyPrefix + "_" + prefix + "_" + secret, prefix, nil
+}
+
+func ParseApiKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+func HashApiKey(key string) string {
+	return HashToken(key)
+}
+
+func CompareApiKeyHash(key string, hash string) bool {
+	return CompareTokenHash(key, hash)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -29,6 +30,15 @@ func JsonSuccess(ctx *fiber.Ctx, data interface{}) error {
 	})
 }
 
+func JsonAccepted(ctx *fiber.Ctx, data interface{}) error {
+	return ctx.Status(fiber.StatusAccepted).JSON(DefaultResponse{
+		Success: true,
+		Status:  fiber.StatusAccepted,
+		Message: "Accepted",
+		Data:    data,
+	})
+}
+
 
 func JsonError(ctx *fiber.Ctx, err error, code string) error {
 	errorMessage := logErrorFormat(err, code)
@@ -67,6 +77,33 @@ func JsonErrorInternal(ctx *fiber.Ctx, err error, code string) error {
 	})
 }
 
+func JsonErrorUnavailable(ctx *fiber.Ctx, err error, code string, retryAfter int) error {
+	errorMessage := logErrorFormat(err, code)
+	Logger.Info(errorMessage)
+	Logger.Error(errorMessage)
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return ctx.Status(fiber.StatusServiceUnavailable).JSON(DefaultResponse{
+		Success: false,
+		Status:  fiber.StatusServiceUnavailable,
+		Code:    code,
+		Message: err.Error(),
+		Data:    nil,
+	})
+}
+
+func JsonErrorTimeout(ctx *fiber.Ctx, err error, code string) error {
+	errorMessage := logErrorFormat(err, code)
+	Logger.Info(errorMessage)
+	Logger.Error(errorMessage)
+	return ctx.Status(fiber.StatusGatewayTimeout).JSON(DefaultResponse{
+		Success: false,
+		Status:  fiber.StatusGatewayTimeout,
+		Code:    code,
+		Message: err.Error(),
+		Data:    nil,
+	})
+}
+
 func JsonErrorValidation(ctx *fiber.Ctx, err error) error {
 	errorMessage := logErrorFormat(err, "E_VALIDATION")
 	Logger.Info(errorMessage)
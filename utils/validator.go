@@ -44,6 +44,12 @@ func getErrorMessage(e validator.FieldError) string {
 		return "only letters and numbers allowed"
 	case "nefield":
 		return "must be different from " + e.Param()
+	case "required_with":
+		return "this field is required with " + strings.ToLower(e.Param())
+	case "oneof":
+		return "must be one of: " + e.Param()
+	case "datetime":
+		return "must use the format " + e.Param()
 	default:
 		return "invalid value"
 	}